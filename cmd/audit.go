@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"aws-groups-manager/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+var auditFile string
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the local mutation audit log",
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the audit log hash chain for gaps or edits",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		auditLog, err := openAuditLog()
+		if err != nil {
+			return err
+		}
+
+		count, problems, err := auditLog.Verify()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch {
+		case jsonOutput():
+			if err := writeJSON(cmd, auditVerifyJSON(auditLog.Path(), count, problems)); err != nil {
+				return err
+			}
+		case len(problems) == 0:
+			fmt.Fprintf(out, "OK: %d records verified in %s\n", count, auditLog.Path())
+		default:
+			for _, p := range problems {
+				fmt.Fprintln(out, p.String())
+			}
+		}
+
+		if len(problems) == 0 {
+			return nil
+		}
+		return fmt.Errorf("audit log verification failed: %d problems in %d records", len(problems), count)
+	},
+}
+
+type auditProblemJSON struct {
+	Line   int    `json:"line"`
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+func auditVerifyJSON(path string, count int, problems []audit.Problem) any {
+	entries := make([]auditProblemJSON, 0, len(problems))
+	for _, p := range problems {
+		entries = append(entries, auditProblemJSON{Line: p.Line, Seq: p.Seq, Reason: p.Reason})
+	}
+	return struct {
+		Path     string             `json:"path"`
+		Records  int                `json:"records"`
+		OK       bool               `json:"ok"`
+		Problems []auditProblemJSON `json:"problems"`
+	}{path, count, len(problems) == 0, entries}
+}
+
+func openAuditLog() (*audit.Log, error) {
+	if auditFile != "" {
+		return audit.OpenPath(auditFile)
+	}
+	return audit.Open()
+}
+
+func init() {
+	auditCmd.PersistentFlags().StringVar(&auditFile, "file", "", "Audit log path (default ~/.config/aws-groups-manager/audit.jsonl)")
+	auditCmd.AddCommand(auditVerifyCmd)
+}
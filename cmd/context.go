@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+
+	"aws-groups-manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage named contexts bundling profile, region and Identity Center instance",
+}
+
+var contextAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Save --profile, --region and --instance-arn under a name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		if !flags.Changed("profile") || !flags.Changed("region") {
+			return fmt.Errorf("context add needs --profile and --region")
+		}
+		ctx := config.Context{
+			Profile: opts.profile,
+			Region:  opts.region,
+		}
+		if flags.Changed("instance-arn") {
+			ctx.InstanceARN = opts.instanceARN
+		}
+		if err := opts.contexts.Add(args[0], ctx); err != nil {
+			return err
+		}
+		fmt.Fprintf(textOut(cmd), "Context %q saved (profile %s, region %s)\n", args[0], ctx.Profile, ctx.Region)
+		return nil
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a context the default for future runs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := opts.contexts.Use(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(textOut(cmd), "Switched to context %q\n", args[0])
+		return nil
+	},
+}
+
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved contexts",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if jsonOutput() {
+			return writeJSON(cmd, contextsJSON())
+		}
+
+		out := cmd.OutOrStdout()
+		names := opts.contexts.Names()
+		if len(names) == 0 {
+			fmt.Fprintln(out, "No contexts; create one with `context add <name> --profile <p> --region <r>`")
+			return nil
+		}
+		for _, name := range names {
+			ctx := opts.contexts.Contexts[name]
+			marker := " "
+			if name == opts.contexts.Current {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "%s %-16s %-24s %-16s %s\n", marker, name, ctx.Profile, ctx.Region, cmp.Or(ctx.InstanceARN, "-"))
+		}
+		return nil
+	},
+}
+
+type contextJSON struct {
+	Name        string `json:"name"`
+	Current     bool   `json:"current"`
+	Profile     string `json:"profile"`
+	Region      string `json:"region"`
+	InstanceARN string `json:"instance_arn,omitempty"`
+}
+
+func contextsJSON() []contextJSON {
+	entries := make([]contextJSON, 0, len(opts.contexts.Contexts))
+	for _, name := range opts.contexts.Names() {
+		ctx := opts.contexts.Contexts[name]
+		entries = append(entries, contextJSON{
+			Name:        name,
+			Current:     name == opts.contexts.Current,
+			Profile:     ctx.Profile,
+			Region:      ctx.Region,
+			InstanceARN: ctx.InstanceARN,
+		})
+	}
+	return entries
+}
+
+func init() {
+	contextCmd.AddCommand(contextAddCmd)
+	contextCmd.AddCommand(contextUseCmd)
+	contextCmd.AddCommand(contextListCmd)
+}
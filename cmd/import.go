@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+type importOptions struct {
+	yes        bool
+	checkpoint string
+	restart    bool
+}
+
+var importOpts importOptions
+
+var importCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "Apply group memberships and account assignments from a CSV file",
+	Long: "Each row is either group,user (user name, email or user ID) or group,account,permission_set " +
+		"(account ID or name, permission set name or ARN). Every row is validated against live data and previewed " +
+		"before anything is changed. Progress is checkpointed so an interrupted import resumes where it stopped.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		out := textOut(cmd)
+		in := bufio.NewReader(cmd.InOrStdin())
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		rows, err := importer.Parse(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(rows) == 0 {
+			fmt.Fprintln(out, "No rows to import")
+			return nil
+		}
+
+		digest := importer.Digest(data)
+		path := importOpts.checkpoint
+		if path == "" {
+			path, err = importer.DefaultCheckpointPath(digest)
+			if err != nil {
+				return err
+			}
+		}
+		source, _ := filepath.Abs(args[0])
+		if importOpts.restart {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		checkpoint, err := importer.LoadCheckpoint(path, source, digest)
+		if err != nil {
+			return err
+		}
+
+		svc, err := openSession(ctx, cmd, in)
+		if err != nil {
+			return err
+		}
+
+		plan, err := importer.Validate(ctx, svc, rows)
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		for _, op := range plan.Ops {
+			status := "add"
+			switch {
+			case op.Err != nil:
+				status = "invalid"
+			case op.Exists:
+				status = "exists"
+			case checkpoint.IsDone(op.Row.Line):
+				status = "done"
+			default:
+				pending++
+			}
+			fmt.Fprintf(out, "  line %-4d %-8s %s\n", op.Row.Line, status, op.Label())
+			if op.Err != nil {
+				fmt.Fprintf(out, "            %v\n", op.Err)
+			}
+		}
+
+		invalid := plan.Invalid()
+		fmt.Fprintf(out, "Rows: %d, to apply: %d, already present: %d, invalid: %d\n", len(plan.Ops), pending, len(plan.Ops)-pending-len(invalid), len(invalid))
+		if checkpoint.Len() > 0 {
+			fmt.Fprintf(out, "Resuming from checkpoint %s (%d rows applied earlier)\n", checkpoint.Path(), checkpoint.Len())
+		}
+
+		if len(invalid) > 0 {
+			return fmt.Errorf("%d rows failed validation; fix the CSV and run again", len(invalid))
+		}
+		if pending == 0 {
+			fmt.Fprintln(out, "Nothing to apply")
+			if jsonOutput() {
+				if err := writeJSON(cmd, importJSON{File: source, Rows: len(plan.Ops), changeReportJSON: changeReportToJSON(awsvc.ChangeReport{})}); err != nil {
+					return err
+				}
+			}
+			return checkpoint.Remove()
+		}
+
+		if !importOpts.yes && !confirm(in, out, fmt.Sprintf("Apply %d changes?", pending)) {
+			return fmt.Errorf("aborted")
+		}
+
+		report, err := importer.Apply(ctx, svc, plan, checkpoint)
+		for _, res := range report.Results {
+			if res.Err != nil {
+				fmt.Fprintf(out, "  FAILED %s %s: %v\n", res.Action, res.Target, res.Err)
+			}
+		}
+		fmt.Fprintln(out, report.Summary())
+		if jsonOutput() {
+			if err := writeJSON(cmd, importJSON{File: source, Rows: len(plan.Ops), Checkpoint: checkpoint.Path(), changeReportJSON: changeReportToJSON(report)}); err != nil {
+				return err
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, importer.ErrThrottled) {
+				return fmt.Errorf("%w\nprogress saved to %s; run the same command again to continue", err, checkpoint.Path())
+			}
+			return err
+		}
+		if report.Failed() > 0 {
+			return fmt.Errorf("%d rows failed; progress saved to %s, run the same command again to retry them", report.Failed(), checkpoint.Path())
+		}
+
+		return checkpoint.Remove()
+	},
+}
+
+type importJSON struct {
+	File       string `json:"file"`
+	Rows       int    `json:"rows"`
+	Checkpoint string `json:"checkpoint"`
+	changeReportJSON
+}
+
+func init() {
+	importCmd.Flags().BoolVarP(&importOpts.yes, "yes", "y", false, "Skip the confirmation prompt")
+	importCmd.Flags().StringVar(&importOpts.checkpoint, "checkpoint", "", "Checkpoint path (default ~/.config/aws-groups-manager/imports/<digest>.json)")
+	importCmd.Flags().BoolVar(&importOpts.restart, "restart", false, "Ignore an existing checkpoint and start over")
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/offboard"
+	"aws-groups-manager/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+type offboardOptions struct {
+	yes    bool
+	output string
+
+	publicKey   string
+	fingerprint string
+}
+
+var offboardOpts offboardOptions
+
+var offboardCmd = &cobra.Command{
+	Use:   "offboard <user>",
+	Short: "Remove a user from every group and delete their direct assignments",
+	Long:  "Resolve <user> by user name, email or user ID, remove every group membership and direct account assignment, and write a signed report.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		out := textOut(cmd)
+		in := bufio.NewReader(cmd.InOrStdin())
+
+		svc, err := openSession(ctx, cmd, in)
+		if err != nil {
+			return err
+		}
+
+		user, err := svc.FindUser(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("resolve user %q: %w", args[0], err)
+		}
+
+		plan, err := svc.PlanOffboard(ctx, user, nil)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "User: %s (%s, %s)\n", user.DisplayName, user.UserName, user.ID)
+		fmt.Fprintf(out, "Group memberships to remove: %d\n", len(plan.Memberships))
+		for _, m := range plan.Memberships {
+			fmt.Fprintf(out, "  - %s (%s)\n", m.GroupName, m.GroupID)
+		}
+		fmt.Fprintf(out, "Direct assignments to delete: %d\n", len(plan.Assignments))
+		for _, a := range plan.Assignments {
+			fmt.Fprintf(out, "  - %s on %s\n", a.PermissionSetARN, a.AccountID)
+		}
+		if len(plan.Inherited) > 0 {
+			fmt.Fprintf(out, "Access inherited from groups (ends with the memberships above): %d\n", len(plan.Inherited))
+			for _, a := range plan.Inherited {
+				fmt.Fprintf(out, "  - %s on %s via group %s\n", a.PermissionSetARN, a.AccountID, a.InheritedFrom)
+			}
+		}
+
+		if len(plan.Memberships) == 0 && len(plan.Assignments) == 0 {
+			fmt.Fprintln(out, "Nothing to remove")
+			if jsonOutput() {
+				return writeJSON(cmd, offboardJSON{User: user.ID, changeReportJSON: changeReportToJSON(awsvc.ChangeReport{})})
+			}
+			return nil
+		}
+
+		if !offboardOpts.yes && !confirm(in, out, "Proceed?") {
+			return fmt.Errorf("aborted")
+		}
+
+		changes := svc.ExecuteOffboard(ctx, plan)
+		report := offboard.NewReport(svc, plan, changes)
+
+		keyPath, err := signing.DefaultKeyPath()
+		if err != nil {
+			return err
+		}
+		if err := report.Sign(keyPath); err != nil {
+			return err
+		}
+
+		path := offboardOpts.output
+		if path == "" {
+			if path, err = offboard.DefaultReportPath(user, report.GeneratedAt); err != nil {
+				return err
+			}
+		}
+		if err := report.Write(path); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+
+		fmt.Fprintf(out, "%s\nSigned report: %s\n", changes.Summary(), path)
+		if jsonOutput() {
+			if err := writeJSON(cmd, offboardJSON{User: user.ID, Report: path, changeReportJSON: changeReportToJSON(changes)}); err != nil {
+				return err
+			}
+		}
+		if changes.Failed() > 0 {
+			return fmt.Errorf("offboarding incomplete: %d operations failed", changes.Failed())
+		}
+		return nil
+	},
+}
+
+var offboardVerifyCmd = &cobra.Command{
+	Use:   "verify-report <file>",
+	Short: "Verify the signature of an offboarding report",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := offboard.ReadReport(args[0])
+		if err != nil {
+			return err
+		}
+		trusted, err := trustedReportKey(report)
+		if err != nil {
+			return err
+		}
+		if err := report.Verify(trusted); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		fingerprint, err := signing.Fingerprint(trusted)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "OK: signature valid (key %s)\n", fingerprint)
+		return nil
+	},
+}
+
+type offboardJSON struct {
+	User   string `json:"user"`
+	Report string `json:"report,omitempty"`
+	changeReportJSON
+}
+
+func trustedReportKey(report offboard.Report) (string, error) {
+	switch {
+	case offboardOpts.publicKey != "":
+		return offboardOpts.publicKey, nil
+	case offboardOpts.fingerprint != "":
+		fingerprint, err := signing.Fingerprint(report.PublicKey)
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(fingerprint, offboardOpts.fingerprint) {
+			return "", fmt.Errorf("report key %s does not match --fingerprint: %w", fingerprint, signing.ErrUntrustedKey)
+		}
+		return report.PublicKey, nil
+	}
+
+	keyPath, err := signing.DefaultKeyPath()
+	if err != nil {
+		return "", err
+	}
+	key, err := signing.LoadPublicKey(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("load trusted key from %s (or pass --public-key/--fingerprint): %w", keyPath, err)
+	}
+	return key, nil
+}
+
+func confirm(in *bufio.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", prompt)
+	line, err := readLine(in)
+	if err != nil && line == "" {
+		return false
+	}
+	answer := strings.ToLower(line)
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	offboardCmd.Flags().BoolVarP(&offboardOpts.yes, "yes", "y", false, "Skip the confirmation prompt")
+	offboardCmd.Flags().StringVarP(&offboardOpts.output, "output", "o", "", "Report path (default ~/.config/aws-groups-manager/reports/offboard-<user>-<timestamp>.json)")
+	offboardVerifyCmd.Flags().StringVar(&offboardOpts.publicKey, "public-key", "", "Trusted base64 ed25519 public key (default: the local signing.key)")
+	offboardVerifyCmd.Flags().StringVar(&offboardOpts.fingerprint, "fingerprint", "", "Trusted key fingerprint (SHA256:<hex>) to pin instead of a full key")
+	offboardVerifyCmd.MarkFlagsMutuallyExclusive("public-key", "fingerprint")
+	offboardCmd.AddCommand(offboardVerifyCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type changeResultJSON struct {
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Error  string `json:"error,omitempty"`
+}
+
+type changeReportJSON struct {
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []changeResultJSON `json:"results"`
+}
+
+func jsonOutput() bool {
+	return opts.settings.OutputFormat == config.OutputJSON
+}
+
+func textOut(cmd *cobra.Command) io.Writer {
+	if jsonOutput() {
+		return cmd.ErrOrStderr()
+	}
+	return cmd.OutOrStdout()
+}
+
+func writeJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func changeReportToJSON(report awsvc.ChangeReport) changeReportJSON {
+	out := changeReportJSON{Succeeded: report.Succeeded(), Failed: report.Failed(), Results: []changeResultJSON{}}
+	for _, res := range report.Results {
+		entry := changeResultJSON{Action: res.Action, Target: res.Target}
+		if res.Err != nil {
+			entry.Error = res.Err.Error()
+		}
+		out.Results = append(out.Results, entry)
+	}
+	return out
+}
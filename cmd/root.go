@@ -1,28 +1,53 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"aws-groups-manager/internal/app"
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/config"
 	"github.com/spf13/cobra"
 )
 
 type rootOptions struct {
-	profile string
-	region  string
+	configPath     string
+	context        string
+	profile        string
+	region         string
+	instanceARN    string
+	identityStore  string
+	assumeRoleARN  string
+	externalID     string
+	mfaSerial      string
+	keys           string
+	theme          string
+	concurrency    int
+	requestTimeout time.Duration
+	pollTimeout    time.Duration
+	outputFormat   string
+	resume         string
+
+	settings config.Config
+	contexts *config.Contexts
 }
 
+const noSettings = "no-settings"
+
 var opts rootOptions
 
 var rootCmd = &cobra.Command{
 	Use:   "aws-groups-manager",
 	Short: "Manage IAM Identity Center groups from a TUI",
-	RunE: func(cmd *cobra.Command, _ []string) error {
-		cfg := app.StartConfig{
-			Profile: opts.profile,
-			Region:  opts.region,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if cmd.Annotations[noSettings] != "" {
+			return nil
 		}
-		return app.Run(cfg, os.Stdout)
+		return loadSettings(cmd)
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return app.Run(startConfig(), os.Stdout)
 	},
 }
 
@@ -30,11 +55,133 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+func loadSettings(cmd *cobra.Command) error {
+	path := opts.configPath
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	contextsPath, err := config.DefaultContextsPath()
+	if err != nil {
+		return err
+	}
+	contexts, err := config.LoadContexts(contextsPath)
+	if err != nil {
+		return fmt.Errorf("load contexts: %w", err)
+	}
+
+	cfg, err := config.Load(path, contexts, opts.context)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintln(cmd.ErrOrStderr(), "warning:", warning)
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("profile") {
+		cfg.Profile = opts.profile
+	}
+	if flags.Changed("region") {
+		cfg.Region = opts.region
+	}
+	if flags.Changed("instance-arn") || flags.Changed("identity-store-id") {
+		cfg.InstanceARN = opts.instanceARN
+		cfg.IdentityStoreID = opts.identityStore
+	}
+	if flags.Changed("assume-role-arn") {
+		cfg.AssumeRoleARN = opts.assumeRoleARN
+	}
+	if flags.Changed("external-id") {
+		cfg.ExternalID = opts.externalID
+	}
+	if flags.Changed("mfa-serial") {
+		cfg.MFASerial = opts.mfaSerial
+	}
+	if flags.Changed("keys") {
+		cfg.Keymap = opts.keys
+	}
+	if flags.Changed("theme") {
+		cfg.Theme = opts.theme
+	}
+	if flags.Changed("concurrency") {
+		cfg.Concurrency = opts.concurrency
+	}
+	if flags.Changed("request-timeout") {
+		cfg.Timeouts.Request = opts.requestTimeout
+	}
+	if flags.Changed("poll-timeout") {
+		cfg.Timeouts.Poll = opts.pollTimeout
+	}
+	if flags.Changed("output-format") {
+		cfg.OutputFormat = opts.outputFormat
+	}
+	if flags.Changed("resume") {
+		cfg.Resume = opts.resume
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	opts.settings = cfg
+	opts.contexts = contexts
+	return nil
+}
+
+func serviceOptions() awsvc.Options {
+	return awsvc.Options{
+		Concurrency:    opts.settings.Concurrency,
+		RequestTimeout: opts.settings.Timeouts.Request,
+		PollTimeout:    opts.settings.Timeouts.Poll,
+		AssumeRoleARN:  opts.settings.AssumeRoleARN,
+		ExternalID:     opts.settings.ExternalID,
+		MFASerial:      opts.settings.MFASerial,
+	}
+}
+
+func startConfig() app.StartConfig {
+	return app.StartConfig{
+		Profile:         opts.settings.Profile,
+		Region:          opts.settings.Region,
+		InstanceARN:     opts.settings.InstanceARN,
+		IdentityStoreID: opts.settings.IdentityStoreID,
+		Context:         opts.settings.Context,
+		Contexts:        opts.contexts.Contexts,
+		KeyPreset:       opts.settings.Keymap,
+		KeyBindings:     opts.settings.KeyBindings,
+		Theme:           opts.settings.Theme,
+		Resume:          opts.settings.Resume,
+		Service:         serviceOptions(),
+	}
+}
+
 func init() {
-	rootCmd.PersistentFlags().StringVar(&opts.profile, "profile", "", "AWS profile name")
-	rootCmd.PersistentFlags().StringVar(&opts.region, "region", "", "AWS region")
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", "", "Config file (default ~/.config/aws-groups-manager/config.yaml)")
+	flags.StringVar(&opts.context, "context", "", "Named context to use for this run (default: the current context set by context use)")
+	flags.StringVar(&opts.profile, "profile", "", "AWS profile name, or none for the default credential chain (environment, container or instance role)")
+	flags.StringVar(&opts.region, "region", "", "AWS region")
+	flags.StringVar(&opts.instanceARN, "instance-arn", "", "Identity Center instance ARN (skips instance selection)")
+	flags.StringVar(&opts.identityStore, "identity-store-id", "", "Identity store ID of the instance to use (skips instance selection)")
+	flags.StringVar(&opts.assumeRoleARN, "assume-role-arn", "", "Role to assume on top of the profile's credentials")
+	flags.StringVar(&opts.externalID, "external-id", "", "External ID for --assume-role-arn")
+	flags.StringVar(&opts.mfaSerial, "mfa-serial", "", "MFA device ARN for --assume-role-arn; the code is prompted for")
+	flags.StringVar(&opts.keys, "keys", "", "Key binding preset: ctrl or vim (default ctrl)")
+	flags.StringVar(&opts.theme, "theme", "", "Color theme: dark or high-contrast")
+	flags.IntVar(&opts.concurrency, "concurrency", config.DefaultConcurrency, "Maximum parallel AWS read calls")
+	flags.DurationVar(&opts.requestTimeout, "request-timeout", config.DefaultRequestTimeout, "Timeout for a single AWS API request (0 disables)")
+	flags.DurationVar(&opts.pollTimeout, "poll-timeout", config.DefaultPollTimeout, "How long to wait for assignment changes to finish (0 waits forever)")
+	flags.StringVar(&opts.outputFormat, "output-format", config.OutputText, "CLI output format: text or json")
+	flags.StringVar(&opts.resume, "resume", config.ResumeSession, "Resume the last TUI session: off, session (profile, region, instance) or group (also the last group and tab)")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(tuiCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(offboardCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(contextCmd)
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aws-groups-manager/internal/audit"
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/profiles"
+	"github.com/spf13/cobra"
+)
+
+func openSession(ctx context.Context, cmd *cobra.Command, in *bufio.Reader) (*awsvc.Service, error) {
+	profile, region := opts.settings.Profile, opts.settings.Region
+	if profile == "" {
+		profile = profiles.NoProfile
+	}
+	if region == "" {
+		region = profiles.Region(profile)
+	}
+	if region == "" && profile == profiles.NoProfile {
+		return nil, fmt.Errorf("no region set; pass --region or set AWS_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("profile %s has no region or sso_region; pass --region", profile)
+	}
+
+	auditLog, err := audit.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+
+	svc, instances, err := connect(ctx, cmd, in, profile, region, auditLog)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := awsvc.SelectInstance(instances, opts.settings.InstanceARN, opts.settings.IdentityStoreID)
+	if errors.Is(err, awsvc.ErrMultipleInstances) {
+		return nil, fmt.Errorf("found %d Identity Center instances; pick one with --instance-arn or --identity-store-id", len(instances))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, region)
+	}
+
+	if instance.Region != region {
+		if svc, _, err = connect(ctx, cmd, in, profile, instance.Region, auditLog); err != nil {
+			return nil, err
+		}
+	}
+	svc.SetInstance(instance.ARN, instance.IdentityStore)
+	return svc, nil
+}
+
+func connect(ctx context.Context, cmd *cobra.Command, in *bufio.Reader, profile, region string, auditLog *audit.Log) (*awsvc.Service, []awsvc.Instance, error) {
+	svc := awsvc.NewService(profile, region)
+	svc.SetAuditLog(auditLog)
+	svc.SetOptions(serviceOptions())
+	svc.SetLoginPrompt(func(auth awsvc.DeviceAuthorization) {
+		url := auth.VerificationURIComplete
+		if url == "" {
+			url = auth.VerificationURI
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "SSO login required for profile %s.\nOpen %s and confirm the code %s (expires %s).\n", profile, url, auth.UserCode, auth.ExpiresAt.Format("15:04:05"))
+	})
+	svc.SetMFAPrompt(func(serial string) (string, error) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "MFA code for %s: ", serial)
+		code, err := readLine(in)
+		if code == "" && err != nil {
+			return "", fmt.Errorf("read MFA code: %w", err)
+		}
+		return code, nil
+	})
+
+	instances, err := svc.EnsureSession(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return svc, instances, nil
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	return strings.TrimSpace(line), err
+}
@@ -11,7 +11,6 @@ var tuiCmd = &cobra.Command{
 	Use:   "tui",
 	Short: "Run interactive TUI",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		cfg := app.StartConfig{Profile: opts.profile, Region: opts.region}
-		return app.Run(cfg, os.Stdout)
+		return app.Run(startConfig(), os.Stdout)
 	},
 }
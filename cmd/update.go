@@ -10,6 +10,9 @@ import (
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Self-update from latest GitHub release",
+	Annotations: map[string]string{
+		noSettings: "true",
+	},
 	RunE: func(_ *cobra.Command, _ []string) error {
 		return updater.Run(context.Background())
 	},
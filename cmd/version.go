@@ -10,6 +10,9 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print build version",
+	Annotations: map[string]string{
+		noSettings: "true",
+	},
 	Run: func(_ *cobra.Command, _ []string) {
 		fmt.Println(version.String())
 	},
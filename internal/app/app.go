@@ -1,27 +1,52 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
+	"aws-groups-manager/internal/audit"
 	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/config"
+	"aws-groups-manager/internal/offboard"
+	"aws-groups-manager/internal/profiles"
+	"aws-groups-manager/internal/signing"
 	"aws-groups-manager/internal/theme"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type StartConfig struct {
-	Profile string
-	Region  string
+	Profile         string
+	Region          string
+	InstanceARN     string
+	IdentityStoreID string
+	Context         string
+	Contexts        map[string]config.Context
+	KeyPreset       string
+	KeyBindings     map[string][]string
+	Theme           string
+	Resume          string
+	Service         awsvc.Options
 }
 
+const (
+	smallDirectoryUsers = 500
+	userSearchLimit     = 50
+	userSearchDelay     = 300 * time.Millisecond
+	paletteLimit        = 30
+)
+
 type screen int
 
 const (
@@ -31,6 +56,9 @@ const (
 	screenInstance
 	screenGroups
 	screenGroupDetail
+	screenAudit
+	screenCompare
+	screenEntity
 )
 
 type detailTab int
@@ -64,13 +92,41 @@ const (
 	modalPermissionSetPicker
 	modalAssignmentCreateConfirm
 	modalBlockingError
+	modalUndoConfirm
+	modalGroupClone
+	modalReport
+	modalGroupPicker
+	modalTransferConfirm
+	modalSyncConfirm
+	modalMergeConfirm
+	modalMergeDeleteConfirm
+	modalOffboardInput
+	modalOffboardConfirm
+	modalMirrorInput
+	modalMirrorSelect
+	modalUserPaste
+	modalUserPasteConfirm
+	modalUserSearch
+	modalPalette
+	modalCommand
+	modalLogin
+	modalMFAInput
+)
+
+type groupPickPurpose int
+
+const (
+	pickTransferTarget groupPickPurpose = iota
+	pickCompareTarget
+	pickMergeTarget
 )
 
 type uiItem struct {
-	id    string
-	title string
-	desc  string
-	raw   any
+	id     string
+	title  string
+	desc   string
+	raw    any
+	marked bool
 }
 
 func (i uiItem) Title() string       { return i.title }
@@ -96,14 +152,17 @@ type model struct {
 	height int
 
 	startCfg StartConfig
+	auditLog *audit.Log
 
-	screen screen
-	tab    detailTab
-	list   list.Model
+	screen     screen
+	prevScreen screen
+	tab        detailTab
+	list       list.Model
 
 	modal     modalType
 	modalList list.Model
 	input     textinput.Model
+	area      textarea.Model
 
 	status      statusMessage
 	lastErr     error
@@ -114,18 +173,28 @@ type model struct {
 
 	filterEnabled bool
 
-	profile string
-	region  string
+	context      string
+	profile      string
+	region       string
+	wantInstance string
+	wantStore    string
+	sessionPath  string
+	resume       lastSession
+
+	regionFromProfile bool
+	regions           []string
 
 	svc       *awsvc.Service
 	instances []awsvc.Instance
 	instance  awsvc.Instance
 
-	groups      []awsvc.Group
-	groupCounts map[string]int
-	group       awsvc.Group
+	groups       []awsvc.Group
+	groupCounts  map[string]int
+	group        awsvc.Group
+	markedGroups map[string]bool
 
-	users []awsvc.GroupUser
+	users       []awsvc.GroupUser
+	markedUsers map[string]bool
 
 	accounts            []awsvc.Account
 	permissionSets      []awsvc.PermissionSet
@@ -138,7 +207,76 @@ type model struct {
 	pendingRemoveUser     awsvc.GroupUser
 	pendingRemoveAssign   awsvc.Assignment
 
+	lastInverse *inverseOp
+
+	cloneSource      awsvc.Group
+	cloneMembers     bool
+	cloneAssignments bool
+	cloneFocus       int
+
+	reportTitle string
+	report      awsvc.ChangeReport
+
+	groupPickFor   groupPickPurpose
+	transferUsers  []awsvc.GroupUser
+	transferTarget awsvc.Group
+	transferMove   bool
+
+	compareSource awsvc.Group
+	comparison    awsvc.GroupComparison
+	syncFromA     bool
+	syncPrune     bool
+	syncFocus     int
+
+	mergeSources []awsvc.Group
+	mergeTarget  awsvc.Group
+	mergeDelete  bool
+
+	offboardPlan awsvc.OffboardPlan
+
+	mirrorStep     int
+	mirrorRefQuery string
+	mirrorRef      awsvc.User
+	mirrorTarget   awsvc.User
+	mirrorGroups   []awsvc.UserMembership
+	mirrorSelected map[string]bool
+	mirrorExisting int
+
+	pasteResolved   []awsvc.User
+	pasteUnresolved []awsvc.UnresolvedUser
+	pasteMembers    int
+
+	auditRecords []audit.Record
+
 	discoverCancel context.CancelFunc
+
+	loginPrompts chan loginPromptMsg
+	login        awsvc.DeviceAuthorization
+	loginCancel  context.CancelFunc
+	loginReturn  modalType
+	mfaPrompts   chan mfaPromptMsg
+	mfa          mfaPromptMsg
+	mfaInput     textinput.Model
+	mfaReturn    modalType
+
+	searchSeq    int
+	searchCancel context.CancelFunc
+	searchUsers  []awsvc.User
+
+	searchIndex  *awsvc.SearchIndex
+	paletteLocal []awsvc.SearchResult
+	paletteUsers []awsvc.SearchResult
+
+	entityTitle  string
+	entityItems  []list.Item
+	entityLoad   tea.Cmd
+	entityReturn screen
+
+	commandSuggestions []string
+	commandProfiles    []string
+	commandContexts    map[string]config.Context
+
+	keys keyMap
 }
 
 type itemDelegate struct {
@@ -155,11 +293,15 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 	i := item.(uiItem)
 	selected := index == m.Index()
 	marker := "  "
-	title := d.styles.NormalTitle.Render(i.title)
+	text := i.title
+	if i.marked {
+		text = "✓ " + text
+	}
+	title := d.styles.NormalTitle.Render(text)
 	desc := d.styles.NormalSub.Render(i.desc)
 	if selected {
 		marker = "▸ "
-		title = d.styles.SelectedTitle.Render(i.title)
+		title = d.styles.SelectedTitle.Render(text)
 		desc = d.styles.SelectedSub.Render(i.desc)
 	}
 	fmt.Fprintf(w, "%s%s\n  %s", marker, title, desc)
@@ -171,6 +313,10 @@ type ensureSessionMsg struct {
 	err       error
 }
 
+type regionsMsg struct {
+	regions []string
+}
+
 type groupsMsg struct {
 	groups []awsvc.Group
 	err    error
@@ -188,10 +334,43 @@ type usersMsg struct {
 }
 
 type allUsersMsg struct {
-	users []awsvc.User
+	users    []awsvc.User
+	complete bool
+	err      error
+}
+
+type searchTickMsg struct {
+	seq int
+}
+
+type searchIndexMsg struct {
+	index awsvc.SearchIndex
+	err   error
+}
+
+type paletteUsersMsg struct {
+	ctx    context.Context
+	seq    int
+	query  string
+	search *awsvc.UserSearch
+	users  []awsvc.User
+	err    error
+}
+
+type entityMsg struct {
+	title string
+	items []list.Item
 	err   error
 }
 
+type userSearchMsg struct {
+	ctx    context.Context
+	seq    int
+	search *awsvc.UserSearch
+	users  []awsvc.User
+	err    error
+}
+
 type accountsDiscoveryMsg struct {
 	accounts       []awsvc.Account
 	permissionSets []awsvc.PermissionSet
@@ -203,17 +382,95 @@ type accountsDiscoveryMsg struct {
 type mutationMsg struct {
 	operation string
 	err       error
+	inverse   *inverseOp
+}
+
+type bulkMsg struct {
+	operation string
+	report    awsvc.ChangeReport
+	err       error
+}
+
+type compareMsg struct {
+	comparison     awsvc.GroupComparison
+	permissionSets []awsvc.PermissionSet
+	err            error
+}
+
+type offboardPlanMsg struct {
+	plan awsvc.OffboardPlan
+	err  error
+}
+
+type mirrorPlanMsg struct {
+	ref      awsvc.User
+	target   awsvc.User
+	groups   []awsvc.UserMembership
+	existing int
+	err      error
+}
+
+type pasteResolvedMsg struct {
+	resolved   []awsvc.User
+	unresolved []awsvc.UnresolvedUser
+	members    int
+}
+
+type mergeMsg struct {
+	operation string
+	report    awsvc.ChangeReport
+	err       error
+}
+
+type inverseOp struct {
+	label string
+	cmd   tea.Cmd
+}
+
+type auditMsg struct {
+	records  []audit.Record
+	problems int
+	err      error
 }
 
 func Run(cfg StartConfig, output io.Writer) error {
-	m := newModel(cfg)
+	auditLog, err := audit.Open()
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+
+	keys, err := loadKeyMap(cfg.KeyPreset, cfg.KeyBindings)
+	if err != nil {
+		return fmt.Errorf("load key bindings: %w", err)
+	}
+
+	if _, err := theme.ByName(cfg.Theme); err != nil {
+		return err
+	}
+
+	sessionPath, err := lastSessionPath()
+	if err != nil {
+		return err
+	}
+	cfg, resume := restoreSession(cfg, loadLastSession(sessionPath))
+	regionFromProfile := false
+	if cfg.Profile != "" && cfg.Region == "" {
+		cfg.Region = profiles.Region(cfg.Profile)
+		regionFromProfile = cfg.Region != ""
+	}
+
+	m := newModel(cfg, auditLog)
+	m.keys = keys
+	m.sessionPath = sessionPath
+	m.resume = resume
+	m.regionFromProfile = regionFromProfile
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(output))
-	_, err := p.Run()
+	_, err = p.Run()
 	return err
 }
 
-func newModel(cfg StartConfig) model {
-	styles := theme.Dark()
+func newModel(cfg StartConfig, auditLog *audit.Log) model {
+	styles, _ := theme.ByName(cfg.Theme)
 	sp := spinner.New()
 	sp.Spinner = spinner.MiniDot
 
@@ -221,10 +478,20 @@ func newModel(cfg StartConfig) model {
 		styles:        styles,
 		spin:          sp,
 		startCfg:      cfg,
+		auditLog:      auditLog,
+		keys:          defaultKeyMap(),
+		context:       cfg.Context,
 		profile:       cfg.Profile,
 		region:        cfg.Region,
+		wantInstance:  cfg.InstanceARN,
+		wantStore:     cfg.IdentityStoreID,
+		regions:       awsvc.DefaultRegions,
+		loginPrompts:  make(chan loginPromptMsg, 1),
+		mfaPrompts:    make(chan mfaPromptMsg, 1),
 		status:        statusMessage{level: statusInfo, text: "Ready"},
 		groupCounts:   make(map[string]int),
+		markedUsers:   make(map[string]bool),
+		markedGroups:  make(map[string]bool),
 		filterEnabled: true,
 	}
 
@@ -233,32 +500,46 @@ func newModel(cfg StartConfig) model {
 	m.input = textinput.New()
 	m.input.Prompt = "> "
 	m.input.CharLimit = 120
-
-	if cfg.Region == "" {
-		m.screen = screenRegion
-		m.list.Title = "Select region"
-		m.setListItems(regionsToItems())
-	} else if cfg.Profile == "" {
+	m.mfaInput = textinput.New()
+	m.mfaInput.Prompt = "> "
+	m.mfaInput.Placeholder = "6-digit code"
+	m.mfaInput.CharLimit = 16
+	m.area = textarea.New()
+	m.area.ShowLineNumbers = false
+	m.area.MaxHeight = 0
+	m.area.Placeholder = "One user name, email or user ID per line"
+	m.area.SetHeight(10)
+
+	if cfg.Profile == "" {
 		m.screen = screenProfile
 		m.list.Title = "Select profile"
+	} else if cfg.Region == "" {
+		m.screen = screenRegion
+		m.list.Title = "Select region"
+		m.setListItems(regionsToItems(m.regions))
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Profile %s has no region; select one", cfg.Profile)}
 	} else {
 		m.screen = screenEnsureSession
 		m.busy = true
-		m.status = statusMessage{level: statusInfo, text: "Checking SSO session"}
+		m.status = statusMessage{level: statusInfo, text: "Checking AWS session"}
 	}
 
 	return m
 }
 
 func (m model) Init() tea.Cmd {
-	cmds := []tea.Cmd{m.spin.Tick}
+	cmds := []tea.Cmd{m.spin.Tick, waitLoginPromptCmd(m.loginPrompts), waitMFAPromptCmd(m.mfaPrompts)}
 
 	if m.screen == screenProfile {
 		cmds = append(cmds, loadProfilesCmd())
 	}
 
 	if m.screen == screenEnsureSession {
-		cmds = append(cmds, ensureSessionCmd(m.profile, m.region))
+		cmds = append(cmds, m.ensureSessionCmd())
+	}
+
+	if m.screen == screenRegion {
+		cmds = append(cmds, m.loadProfileRegionsCmd())
 	}
 
 	return tea.Batch(cmds...)
@@ -273,6 +554,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.list.SetSize(max(20, msg.Width-2), max(8, msg.Height-8))
 		m.modalList.SetSize(max(30, msg.Width-10), max(8, msg.Height/2))
+		m.area.SetWidth(max(30, msg.Width-14))
 
 	case spinner.TickMsg:
 		if m.busy {
@@ -281,6 +563,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+	case sessionSavedMsg:
+		if msg.err != nil {
+			m.setStatusErr("Could not save the session for resume", msg.err)
+		}
+
+	case regionsMsg:
+		m.regions = msg.regions
+		if m.screen == screenRegion {
+			m.setListItems(regionsToItems(m.regions))
+		}
+
 	case profilesMsg:
 		m.busy = false
 		if msg.err != nil {
@@ -290,28 +583,47 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setListItems(profilesToItems(msg.profiles))
 		m.status = statusMessage{level: statusInfo, text: "Select an AWS profile"}
 
+	case loginPromptMsg:
+		cmds = append(cmds, m.showLoginPrompt(msg))
+
+	case mfaPromptMsg:
+		cmds = append(cmds, m.showMFAPrompt(msg))
+
+	case loginDoneMsg:
+		m.closeLoginPrompt()
+
+	case browserMsg:
+		if msg.err != nil {
+			m.status = statusMessage{level: statusWarn, text: "Could not open a browser; open the URL manually"}
+		}
+
 	case ensureSessionMsg:
 		m.busy = false
+		m.closeLoginPrompt()
+		if errors.Is(msg.err, context.Canceled) {
+			m.setBlockingError("SSO login cancelled", msg.err, "Pick the profile again with :profile to retry")
+			break
+		}
 		if msg.err != nil {
-			m.setBlockingError("Unable to establish SSO session", msg.err, "Run `aws sso login --profile <profile>` and retry")
+			m.setBlockingError("Unable to establish AWS session", msg.err, "Check the profile's credentials (sso_start_url and sso_region for SSO profiles), then retry with :profile")
 			break
 		}
 
 		m.svc = msg.svc
 		m.instances = msg.instances
+		if regions := msg.svc.Regions(); len(regions) > 0 {
+			m.regions = regions
+		} else {
+			cmds = append(cmds, loadRegionsCmd(msg.svc))
+		}
 		if len(msg.instances) == 0 {
-			m.setBlockingError("No Identity Center instances found", fmt.Errorf("ListInstances returned zero instances"), "Verify account/region and IAM Identity Center setup")
+			m.setBlockingError("No Identity Center instances found", fmt.Errorf("ListInstances returned zero instances"), "Verify account/region and IAM Identity Center setup, or pick region \"all\" to search every region")
 			break
 		}
 
-		if len(msg.instances) == 1 {
-			m.instance = msg.instances[0]
-			m.svc.SetInstance(m.instance.ARN, m.instance.IdentityStore)
-			m.screen = screenGroups
-			m.status = statusMessage{level: statusInfo, text: "Loaded Identity Center instance"}
-			m.configureListForGroups()
-			m.busy = true
-			cmds = append(cmds, loadGroupsCmd(m.svc))
+		instance, err := awsvc.SelectInstance(msg.instances, m.wantInstance, m.wantStore)
+		if err == nil {
+			cmds = append(cmds, m.useInstance(instance))
 			break
 		}
 
@@ -319,6 +631,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list.Title = "Select Identity Center instance"
 		m.setListItems(instancesToItems(msg.instances))
 		m.status = statusMessage{level: statusInfo, text: "Select an instance"}
+		if !errors.Is(err, awsvc.ErrMultipleInstances) {
+			m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Configured %s; select an instance", err)}
+		}
 
 	case groupsMsg:
 		m.busy = false
@@ -327,14 +642,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			break
 		}
 		m.groups = msg.groups
+		m.searchIndex = nil
 		m.groupCounts = map[string]int{}
-		m.setListItems(groupsToItems(msg.groups, "", 0))
+		m.markedGroups = make(map[string]bool)
+		m.setListItems(groupsToItems(msg.groups, "", 0, m.markedGroups))
 		if len(m.groups) > 0 {
 			m.group = m.groups[0]
-			m.setListItems(groupsToItems(m.groups, m.group.ID, unknownCount))
+			m.setListItems(groupsToItems(m.groups, m.group.ID, unknownCount, m.markedGroups))
 			cmds = append(cmds, loadGroupCountCmd(m.svc, m.group.ID))
 		}
 		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Loaded %d groups", len(m.groups))}
+		cmds = append(cmds, m.resumeGroup())
 
 	case groupCountMsg:
 		if msg.err != nil {
@@ -345,20 +663,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.screen == screenGroups {
 			selected := m.currentGroupID()
 			count := m.groupCounts[selected]
-			m.setListItems(groupsToItems(m.groups, selected, count))
+			m.setListItems(groupsToItems(m.groups, selected, count, m.markedGroups))
 		}
 
 	case usersMsg:
 		m.busy = false
 		if msg.err != nil {
+			m.resume = lastSession{}
 			m.setStatusErr("Failed to load group users", msg.err)
 			break
 		}
 		m.users = msg.users
+		m.markedUsers = make(map[string]bool)
 		if m.screen == screenGroupDetail && m.tab == tabUsers {
-			m.setListItems(groupUsersToItems(msg.users))
+			m.setListItems(groupUsersToItems(msg.users, m.markedUsers))
 		}
 		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Loaded %d users", len(msg.users))}
+		cmds = append(cmds, m.resumeTab())
 
 	case allUsersMsg:
 		m.busy = false
@@ -366,11 +687,111 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.setStatusErr("Failed to load users", msg.err)
 			break
 		}
+		if !msg.complete {
+			m.openUserSearch()
+			break
+		}
 		m.modal = modalUserPicker
 		m.modalList.Title = "Select user to add"
 		m.modalList.SetItems(allUsersToItems(msg.users))
 		m.status = statusMessage{level: statusInfo, text: "Choose a user and press Enter"}
 
+	case searchTickMsg:
+		if msg.seq == m.searchSeq && m.modal == modalPalette {
+			cmds = append(cmds, m.searchPalette(msg.seq))
+			break
+		}
+		if msg.seq != m.searchSeq || m.modal != modalUserSearch {
+			break
+		}
+		m.cancelSearch()
+		m.searchUsers = nil
+		query := strings.TrimSpace(m.input.Value())
+		if len(query) < 2 {
+			m.modalList.SetItems(nil)
+			m.status = statusMessage{level: statusInfo, text: "Type at least 2 characters to search"}
+			break
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.searchCancel = cancel
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Searching for %q...", query)}
+		cmds = append(cmds, searchUsersCmd(ctx, m.svc, query, msg.seq))
+
+	case searchIndexMsg:
+		if msg.err != nil {
+			m.setStatusErr("Failed to load search index", msg.err)
+			break
+		}
+		m.searchIndex = &msg.index
+		if m.modal == modalPalette {
+			m.paletteLocal = m.searchIndex.Search(m.input.Value())
+			m.setPaletteItems()
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Indexed %d groups, %d accounts, %d permission sets", len(msg.index.Groups), len(msg.index.Accounts), len(msg.index.PermissionSets))}
+		}
+
+	case paletteUsersMsg:
+		if msg.seq != m.searchSeq || m.modal != modalPalette {
+			break
+		}
+		if msg.err != nil {
+			m.cancelSearch()
+			m.setStatusErr("User search failed", msg.err)
+			break
+		}
+		selected := m.modalList.Index()
+		m.paletteUsers = append(m.paletteUsers, awsvc.UserSearchResults(msg.query, msg.users)...)
+		m.setPaletteItems()
+		m.modalList.Select(selected)
+		if msg.search.Done() || len(m.paletteUsers) >= paletteLimit {
+			m.cancelSearch()
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("%d matching users (%d scanned)", len(m.paletteUsers), msg.search.Scanned())}
+			break
+		}
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Searching users: %d scanned", msg.search.Scanned())}
+		cmds = append(cmds, nextPaletteUsersCmd(msg.ctx, msg.search, msg.query, msg.seq))
+
+	case entityMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.setStatusErr("Failed to load "+m.entityTitle, msg.err)
+			break
+		}
+		m.entityTitle = msg.title
+		m.entityItems = msg.items
+		if m.screen == screenEntity {
+			m.list.Title = msg.title
+			m.setListItems(msg.items)
+		}
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("%s: %d entries", msg.title, len(msg.items))}
+
+	case userSearchMsg:
+		if msg.seq != m.searchSeq || m.modal != modalUserSearch {
+			break
+		}
+		if msg.err != nil {
+			m.cancelSearch()
+			m.setStatusErr("User search failed", msg.err)
+			break
+		}
+		m.searchUsers = append(m.searchUsers, msg.users...)
+		if len(m.searchUsers) > userSearchLimit {
+			m.searchUsers = m.searchUsers[:userSearchLimit]
+		}
+		selected := m.modalList.Index()
+		m.modalList.SetItems(allUsersToItems(m.searchUsers))
+		m.modalList.Select(selected)
+		switch {
+		case len(m.searchUsers) >= userSearchLimit:
+			m.cancelSearch()
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Showing the first %d matches; keep typing to narrow down", userSearchLimit)}
+		case msg.search.Done():
+			m.cancelSearch()
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("%d matching users (%d scanned)", len(m.searchUsers), msg.search.Scanned())}
+		default:
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Searching users: %d matches, %d scanned", len(m.searchUsers), msg.search.Scanned())}
+			cmds = append(cmds, nextSearchUsersCmd(msg.ctx, msg.search, msg.seq))
+		}
+
 	case accountsDiscoveryMsg:
 		m.busy = false
 		m.discoverCancel = nil
@@ -399,6 +820,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.status = statusMessage{level: statusInfo, text: msg.operation + " complete"}
 		m.modal = modalNone
+		m.lastInverse = msg.inverse
 
 		if m.screen == screenGroups {
 			m.busy = true
@@ -415,18 +837,137 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, discoverAccountsAssignmentsCmd(ctx, m.svc, m.group.ID))
 		}
 
+	case bulkMsg:
+		m.busy = false
+		m.lastInverse = nil
+		if msg.err != nil {
+			m.setStatusErr(msg.operation+" failed", msg.err)
+		} else if msg.report.Failed() > 0 {
+			m.status = statusMessage{level: statusWarn, text: msg.operation + ": " + msg.report.Summary()}
+		} else {
+			m.status = statusMessage{level: statusInfo, text: msg.operation + " complete: " + msg.report.Summary()}
+		}
+		if len(msg.report.Results) > 0 {
+			m.reportTitle = msg.operation
+			m.report = msg.report
+			m.modal = modalReport
+		}
+		if cmd := m.refreshCurrentScreen(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case mergeMsg:
+		m.busy = false
+		m.lastInverse = nil
+		m.reportTitle = msg.operation
+		m.report = msg.report
+		switch {
+		case msg.err != nil:
+			m.setStatusErr(msg.operation+" failed", msg.err)
+		case msg.report.Failed() > 0:
+			m.status = statusMessage{level: statusWarn, text: msg.operation + ": " + msg.report.Summary() + "; source groups kept"}
+		default:
+			m.status = statusMessage{level: statusInfo, text: msg.operation + ": " + msg.report.Summary()}
+		}
+		if msg.err == nil && msg.report.Failed() == 0 && m.mergeDelete {
+			m.modal = modalMergeDeleteConfirm
+		} else if len(msg.report.Results) > 0 {
+			m.modal = modalReport
+		}
+		if cmd := m.refreshCurrentScreen(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case offboardPlanMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.setStatusErr("Failed to prepare offboarding", msg.err)
+			break
+		}
+		m.offboardPlan = msg.plan
+		m.modal = modalOffboardConfirm
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Review offboarding of %s", msg.plan.User.DisplayName)}
+
+	case mirrorPlanMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.setStatusErr("Failed to prepare access mirror", msg.err)
+			break
+		}
+		m.mirrorRef = msg.ref
+		m.mirrorTarget = msg.target
+		m.mirrorGroups = msg.groups
+		m.mirrorExisting = msg.existing
+		m.mirrorSelected = make(map[string]bool, len(msg.groups))
+		for _, g := range msg.groups {
+			m.mirrorSelected[g.GroupID] = true
+		}
+		if len(msg.groups) == 0 {
+			m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("%s already has every group of %s", msg.target.DisplayName, msg.ref.DisplayName)}
+			break
+		}
+		m.modal = modalMirrorSelect
+		m.modalList.Title = fmt.Sprintf("Grant %s the groups of %s", msg.target.DisplayName, msg.ref.DisplayName)
+		m.modalList.ResetSelected()
+		m.modalList.SetItems(mirrorGroupsToItems(m.mirrorGroups, m.mirrorSelected))
+		m.status = statusMessage{level: statusInfo, text: "Space deselects groups, Enter grants the rest"}
+
+	case pasteResolvedMsg:
+		m.busy = false
+		m.pasteResolved = msg.resolved
+		m.pasteUnresolved = msg.unresolved
+		m.pasteMembers = msg.members
+		m.modal = modalUserPasteConfirm
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Resolved %d users, %d lines unresolved", len(msg.resolved)+msg.members, len(msg.unresolved))}
+
+	case compareMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.setStatusErr("Failed to compare groups", msg.err)
+			break
+		}
+		if len(msg.permissionSets) > 0 {
+			m.permissionSets = msg.permissionSets
+		}
+		m.comparison = msg.comparison
+		if m.screen == screenCompare {
+			m.setListItems(comparisonToItems(msg.comparison, m.accounts))
+		}
+		c := msg.comparison
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf(
+			"Members: %d only in A, %d only in B, %d shared | Assignments: %d only in A, %d only in B, %d shared",
+			len(c.OnlyAUsers), len(c.OnlyBUsers), len(c.BothUsers),
+			len(c.OnlyAAssignments), len(c.OnlyBAssignments), len(c.BothAssignments),
+		)}
+
+	case auditMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.setStatusErr("Failed to load audit log", msg.err)
+			break
+		}
+		m.auditRecords = msg.records
+		if m.screen == screenAudit {
+			m.setListItems(auditRecordsToItems(msg.records))
+		}
+		if msg.problems > 0 {
+			m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Audit hash chain has %d problems; run `aws-groups-manager audit verify`", msg.problems)}
+		} else {
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Loaded %d audit records (hash chain intact)", len(msg.records))}
+		}
+
 	case tea.KeyMsg:
 		if m.modal != modalNone {
 			if cmd := m.handleModalKeyMsg(msg); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
 		} else {
-			key := msg.String()
+			bound := m.keys.bound(msg.String()) && !m.typingInFilter(msg)
 			if cmd := m.handleKey(msg); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
 
-			if key != "ctrl+c" {
+			if !bound {
 				cmds = append(cmds, m.updateMainList(msg)...)
 			}
 		}
@@ -450,10 +991,10 @@ func (m *model) updateMainList(msg tea.Msg) []tea.Cmd {
 		if selected != "" && selected != prevGroup {
 			m.group = m.groups[m.list.Index()]
 			if _, ok := m.groupCounts[selected]; !ok {
-				m.setListItems(groupsToItems(m.groups, selected, unknownCount))
+				m.setListItems(groupsToItems(m.groups, selected, unknownCount, m.markedGroups))
 				cmds = append(cmds, loadGroupCountCmd(m.svc, selected))
 			} else {
-				m.setListItems(groupsToItems(m.groups, selected, m.groupCounts[selected]))
+				m.setListItems(groupsToItems(m.groups, selected, m.groupCounts[selected], m.markedGroups))
 			}
 		}
 	}
@@ -499,55 +1040,52 @@ func (m model) View() string {
 func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	key := msg.String()
 
-	if key == "ctrl+c" {
-		if m.discoverCancel != nil {
-			m.discoverCancel()
-		}
-		return tea.Quit
+	if m.typingInFilter(msg) {
+		return nil
 	}
 
-	switch key {
-	case "ctrl+g":
+	switch {
+	case m.keys.is(actQuit, key):
+		return m.runQuitCommand("")
+	case m.keys.is(actHelp, key):
 		m.modal = modalHelp
 		return nil
-	case "ctrl+e":
+	case m.keys.is(actErrorDetails, key):
 		if m.lastErr != nil {
 			m.modal = modalErrorDetails
 		}
 		return nil
-	case "ctrl+f":
+	case m.keys.is(actFilter, key):
 		m.filterEnabled = !m.filterEnabled
 		m.list.SetFilteringEnabled(m.filterEnabled)
 		m.status = statusMessage{level: statusInfo, text: "Toggled search/filter"}
 		return nil
-	case "ctrl+r":
+	case m.keys.is(actRefresh, key):
 		return m.refreshCurrentScreen()
+	case m.keys.is(actUndo, key):
+		return m.requestUndo()
+	case m.keys.is(actAuditLog, key):
+		return m.openAuditLog()
+	case m.keys.is(actFind, key):
+		return m.openPalette()
+	case m.keys.is(actCommand, key):
+		m.openCommandLine()
+		return nil
+	}
+
+	switch key {
 	case "enter":
 		return m.handleEnter()
 	case "esc":
 		return m.handleEsc()
 	case "tab", "shift+tab":
 		if m.screen == screenGroupDetail {
-			if m.tab == tabUsers {
-				m.tab = tabAccounts
-				m.configureListForAssignments()
-				m.setListItems(assignmentsToItems(m.assignments))
-				if !m.busy {
-					ctx, cancel := context.WithCancel(context.Background())
-					m.discoverCancel = cancel
-					m.busy = true
-					return discoverAccountsAssignmentsCmd(ctx, m.svc, m.group.ID)
-				}
-			} else {
-				m.tab = tabUsers
-				m.configureListForUsers()
-				m.setListItems(groupUsersToItems(m.users))
-			}
+			return m.toggleTab()
 		}
 		return nil
 	}
 
-	if key == "ctrl+n" && m.screen == screenGroups {
+	if m.keys.is(actCreateGroup, key) && m.screen == screenGroups {
 		m.modal = modalGroupCreateInput
 		m.input.SetValue("")
 		m.input.Placeholder = "Group display name"
@@ -555,7 +1093,91 @@ func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
-	if key == "ctrl+d" && m.screen == screenGroups {
+	if m.keys.is(actCloneGroup, key) && m.screen == screenGroups {
+		idx := m.list.Index()
+		if idx < 0 || idx >= len(m.groups) {
+			return nil
+		}
+		m.cloneSource = m.groups[idx]
+		m.cloneMembers = true
+		m.cloneAssignments = true
+		m.cloneFocus = 0
+		m.modal = modalGroupClone
+		m.input.SetValue(m.cloneSource.DisplayName + "-copy")
+		m.input.Placeholder = "New group display name"
+		m.input.Focus()
+		return nil
+	}
+
+	if m.keys.is(actCompare, key) && m.screen == screenGroups {
+		idx := m.list.Index()
+		if idx < 0 || idx >= len(m.groups) {
+			return nil
+		}
+		m.compareSource = m.groups[idx]
+		m.openGroupPicker(pickCompareTarget, fmt.Sprintf("Compare %s with", m.compareSource.DisplayName), m.compareSource.ID)
+		return nil
+	}
+
+	if m.keys.is(actMark, key) && m.screen == screenGroups {
+		group, ok := highlighted(m.list).raw.(awsvc.Group)
+		if !ok {
+			return nil
+		}
+		if m.markedGroups[group.ID] {
+			delete(m.markedGroups, group.ID)
+		} else {
+			m.markedGroups[group.ID] = true
+		}
+		selected := m.currentGroupID()
+		m.setListItems(groupsToItems(m.groups, selected, m.groupCountOrUnknown(selected), m.markedGroups))
+		return nil
+	}
+
+	if m.keys.is(actOffboard, key) && m.screen == screenGroups {
+		m.modal = modalOffboardInput
+		m.input.SetValue("")
+		m.input.Placeholder = "User name, email or user ID"
+		m.input.Focus()
+		return nil
+	}
+
+	if m.keys.is(actMirrorAccess, key) && m.screen == screenGroups {
+		m.mirrorStep = 0
+		m.modal = modalMirrorInput
+		m.input.SetValue("")
+		m.input.Placeholder = "Reference user: name, email or user ID"
+		m.input.Focus()
+		return nil
+	}
+
+	if m.keys.is(actMerge, key) && m.screen == screenGroups {
+		sources := make([]awsvc.Group, 0, len(m.markedGroups))
+		ids := make([]string, 0, len(m.markedGroups))
+		for _, g := range m.groups {
+			if m.markedGroups[g.ID] {
+				sources = append(sources, g)
+				ids = append(ids, g.ID)
+			}
+		}
+		if len(sources) == 0 {
+			m.status = statusMessage{level: statusWarn, text: "Mark source groups with " + m.keys.label(actMark) + " first"}
+			return nil
+		}
+		m.mergeSources = sources
+		m.openGroupPicker(pickMergeTarget, fmt.Sprintf("Merge %d groups into", len(sources)), ids...)
+		return nil
+	}
+
+	if m.keys.is(actSync, key) && m.screen == screenCompare && !m.busy {
+		m.syncFromA = true
+		m.syncPrune = false
+		m.syncFocus = 0
+		m.modal = modalSyncConfirm
+		return nil
+	}
+
+	if m.keys.is(actDeleteGroup, key) && m.screen == screenGroups {
 		if m.currentGroupID() == "" {
 			return nil
 		}
@@ -564,11 +1186,15 @@ func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	}
 
 	if m.screen == screenGroupDetail && m.tab == tabUsers {
-		if key == "ctrl+a" {
+		if m.keys.is(actAdd, key) {
 			m.busy = true
 			return loadAllUsersCmd(m.svc)
 		}
-		if key == "ctrl+x" {
+		if m.keys.is(actPasteUsers, key) {
+			m.openUserPaste()
+			return nil
+		}
+		if m.keys.is(actRemove, key) {
 			idx := m.list.Index()
 			if idx >= 0 && idx < len(m.users) {
 				m.pendingRemoveUser = m.users[idx]
@@ -576,10 +1202,41 @@ func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			}
 			return nil
 		}
+		if m.keys.is(actMark, key) {
+			user, ok := highlighted(m.list).raw.(awsvc.GroupUser)
+			if !ok {
+				return nil
+			}
+			if m.markedUsers[user.MembershipID] {
+				delete(m.markedUsers, user.MembershipID)
+			} else {
+				m.markedUsers[user.MembershipID] = true
+			}
+			m.setListItems(groupUsersToItems(m.users, m.markedUsers))
+			return nil
+		}
+		if m.keys.is(actOffboard, key) {
+			user, ok := highlighted(m.list).raw.(awsvc.GroupUser)
+			if !ok || m.busy {
+				return nil
+			}
+			m.busy = true
+			return planOffboardCmd(m.svc, user.UserID, true, m.entityNames().groups)
+		}
+		if m.keys.is(actCopyMove, key) {
+			users := m.markedGroupUsers()
+			if len(users) == 0 {
+				m.status = statusMessage{level: statusWarn, text: "No users selected"}
+				return nil
+			}
+			m.transferUsers = users
+			m.openGroupPicker(pickTransferTarget, fmt.Sprintf("Copy or move %d users to group", len(users)), m.group.ID)
+			return nil
+		}
 	}
 
 	if m.screen == screenGroupDetail && m.tab == tabAccounts {
-		if key == "ctrl+a" {
+		if m.keys.is(actAdd, key) {
 			if len(m.permissionSets) == 0 {
 				m.setStatusErr("Cannot add assignment", fmt.Errorf("permission sets are not loaded"))
 				return nil
@@ -599,7 +1256,7 @@ func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			return nil
 		}
 
-		if key == "ctrl+x" {
+		if m.keys.is(actRemove, key) {
 			idx := m.list.Index()
 			if idx >= 0 && idx < len(m.assignments) {
 				m.pendingRemoveAssign = m.assignments[idx]
@@ -615,18 +1272,151 @@ func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
 func (m *model) handleModalKeyMsg(msg tea.KeyMsg) tea.Cmd {
 	key := msg.String()
 
-	if key == "esc" {
+	if m.modal == modalLogin {
+		return m.handleLoginKey(key)
+	}
+	if m.modal == modalMFAInput {
+		return m.handleMFAKey(msg)
+	}
+
+	if m.keys.is(actCancel, key) {
 		if m.modal == modalBlockingError {
 			return nil
 		}
+		if m.modal == modalMergeDeleteConfirm {
+			m.status = statusMessage{level: statusInfo, text: "Source groups kept"}
+			m.modal = modalReport
+			return nil
+		}
+		if m.modal == modalUserSearch || m.modal == modalPalette {
+			m.cancelSearch()
+		}
 		m.modal = modalNone
 		m.input.Blur()
 		return nil
 	}
 
-	if key == "enter" {
+	if m.modal == modalGroupClone && m.handleCloneKey(key) {
+		return nil
+	}
+
+	if m.modal == modalSyncConfirm {
+		switch {
+		case m.keys.is(actNextField, key), key == "up", key == "down":
+			m.syncFocus = 1 - m.syncFocus
+			return nil
+		case m.keys.is(actToggle, key):
+			if m.syncFocus == 0 {
+				m.syncFromA = !m.syncFromA
+			} else {
+				m.syncPrune = !m.syncPrune
+			}
+			return nil
+		}
+	}
+
+	if (m.modal == modalUserPicker || m.modal == modalUserSearch) && m.keys.is(actPickerPaste, key) {
+		m.cancelSearch()
+		m.openUserPaste()
+		return nil
+	}
+
+	if m.modal == modalCommand {
+		switch {
+		case m.keys.is(actNextField, key):
+			m.completeCommandLine()
+			return nil
+		case m.keys.is(actConfirm, key):
+			line := m.input.Value()
+			m.modal = modalNone
+			m.input.Blur()
+			return m.runCommandLine(line)
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		m.updateCommandSuggestions()
+		return cmd
+	}
+
+	if m.modal == modalUserSearch || m.modal == modalPalette {
+		switch {
+		case key == "up", key == "down", key == "pgup", key == "pgdown":
+			var cmd tea.Cmd
+			m.modalList, cmd = m.modalList.Update(msg)
+			return cmd
+		case m.keys.is(actConfirm, key):
+			if m.modal == modalPalette {
+				result, ok := highlighted(m.modalList).raw.(awsvc.SearchResult)
+				if !ok {
+					return nil
+				}
+				m.cancelSearch()
+				m.modal = modalNone
+				m.input.Blur()
+				return m.openSearchResult(result)
+			}
+			item, ok := m.modalList.SelectedItem().(uiItem)
+			if !ok {
+				return nil
+			}
+			m.cancelSearch()
+			m.modal = modalNone
+			m.input.Blur()
+			m.busy = true
+			return addUserCmd(m.svc, m.group, awsvc.GroupUser{UserID: item.id, DisplayName: item.title})
+		}
+
+		before := m.input.Value()
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		if m.input.Value() == before {
+			return cmd
+		}
+		m.searchSeq++
+		seq := m.searchSeq
+		return tea.Batch(cmd, tea.Tick(userSearchDelay, func(time.Time) tea.Msg {
+			return searchTickMsg{seq: seq}
+		}))
+	}
+
+	if m.modal == modalUserPaste {
+		if m.keys.is(actSubmit, key) {
+			queries := pastedQueries(m.area.Value())
+			if len(queries) == 0 {
+				m.status = statusMessage{level: statusWarn, text: "Paste at least one user name or email"}
+				return nil
+			}
+			m.modal = modalNone
+			m.area.Blur()
+			m.busy = true
+			return resolvePastedUsersCmd(m.svc, queries, m.users)
+		}
+		var cmd tea.Cmd
+		m.area, cmd = m.area.Update(msg)
+		return cmd
+	}
+
+	if m.modal == modalMirrorSelect && m.keys.is(actToggle, key) && m.modalList.FilterState() != list.Filtering {
+		if g, ok := highlighted(m.modalList).raw.(awsvc.UserMembership); ok {
+			m.mirrorSelected[g.GroupID] = !m.mirrorSelected[g.GroupID]
+			m.modalList.SetItems(mirrorGroupsToItems(m.mirrorGroups, m.mirrorSelected))
+		}
+		return nil
+	}
+
+	if m.modal == modalMergeConfirm && (m.keys.is(actNextField, key) || m.keys.is(actToggle, key)) {
+		m.mergeDelete = !m.mergeDelete
+		return nil
+	}
+
+	if m.modal == modalTransferConfirm && (m.keys.is(actNextField, key) || m.keys.is(actToggle, key)) {
+		m.transferMove = !m.transferMove
+		return nil
+	}
+
+	if m.keys.is(actConfirm, key) {
 		switch m.modal {
-		case modalHelp, modalErrorDetails:
+		case modalHelp, modalErrorDetails, modalReport:
 			m.modal = modalNone
 		case modalBlockingError:
 			m.modal = modalNone
@@ -651,7 +1441,7 @@ func (m *model) handleModalKeyMsg(msg tea.KeyMsg) tea.Cmd {
 		case modalUserRemoveConfirm:
 			m.modal = modalNone
 			m.busy = true
-			return removeUserCmd(m.svc, m.pendingRemoveUser.MembershipID)
+			return removeUserCmd(m.svc, m.group, m.pendingRemoveUser)
 		case modalUserPicker:
 			idx := m.modalList.Index()
 			if idx < 0 || idx >= len(m.modalList.Items()) {
@@ -660,7 +1450,7 @@ func (m *model) handleModalKeyMsg(msg tea.KeyMsg) tea.Cmd {
 			item := m.modalList.Items()[idx].(uiItem)
 			m.modal = modalNone
 			m.busy = true
-			return addUserCmd(m.svc, m.group.ID, item.id)
+			return addUserCmd(m.svc, m.group, awsvc.GroupUser{UserID: item.id, DisplayName: item.title})
 		case modalAccountPicker:
 			idx := m.modalList.Index()
 			if idx < 0 || idx >= len(m.accounts) {
@@ -696,13 +1486,121 @@ func (m *model) handleModalKeyMsg(msg tea.KeyMsg) tea.Cmd {
 				m.status = statusMessage{level: statusWarn, text: "Select account first"}
 				return nil
 			}
+			accountName := ""
+			if m.selectedManualAccount == "" {
+				accountName = m.selectedAccount.Name
+			}
 			m.modal = modalNone
 			m.busy = true
-			return createAssignmentCmd(m.svc, m.group.ID, accountID, m.selectedPermissionSet.ARN)
+			return createAssignmentCmd(m.svc, m.group, awsvc.Assignment{
+				AccountID:         accountID,
+				AccountName:       accountName,
+				PermissionSetARN:  m.selectedPermissionSet.ARN,
+				PermissionSetName: m.selectedPermissionSet.Name,
+			})
 		case modalAssignmentRemoveConfirm:
 			m.modal = modalNone
 			m.busy = true
-			return deleteAssignmentCmd(m.svc, m.group.ID, m.pendingRemoveAssign.AccountID, m.pendingRemoveAssign.PermissionSetARN)
+			return deleteAssignmentCmd(m.svc, m.group, m.pendingRemoveAssign)
+		case modalGroupClone:
+			name := strings.TrimSpace(m.input.Value())
+			if name == "" {
+				m.status = statusMessage{level: statusWarn, text: "Group name cannot be empty"}
+				return nil
+			}
+			m.modal = modalNone
+			m.input.Blur()
+			m.busy = true
+			m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Cloning %s into %s", m.cloneSource.DisplayName, name)}
+			return cloneGroupCmd(m.svc, m.cloneSource.ID, name, awsvc.CloneOptions{
+				Members:     m.cloneMembers,
+				Assignments: m.cloneAssignments,
+			})
+		case modalGroupPicker:
+			group, ok := highlighted(m.modalList).raw.(awsvc.Group)
+			if !ok {
+				return nil
+			}
+			return m.handleGroupPicked(group)
+		case modalOffboardInput:
+			query := strings.TrimSpace(m.input.Value())
+			if query == "" {
+				m.status = statusMessage{level: statusWarn, text: "Enter a user name, email or user ID"}
+				return nil
+			}
+			m.modal = modalNone
+			m.input.Blur()
+			m.busy = true
+			return planOffboardCmd(m.svc, query, false, m.entityNames().groups)
+		case modalUserPasteConfirm:
+			if len(m.pasteResolved) == 0 {
+				m.modal = modalNone
+				return nil
+			}
+			m.modal = modalNone
+			m.busy = true
+			return addPastedUsersCmd(m.svc, m.group, m.pasteResolved)
+		case modalMirrorInput:
+			query := strings.TrimSpace(m.input.Value())
+			if query == "" {
+				m.status = statusMessage{level: statusWarn, text: "Enter a user name, email or user ID"}
+				return nil
+			}
+			if m.mirrorStep == 0 {
+				m.mirrorRefQuery = query
+				m.mirrorStep = 1
+				m.input.SetValue("")
+				m.input.Placeholder = "New user: name, email or user ID"
+				return nil
+			}
+			m.modal = modalNone
+			m.input.Blur()
+			m.busy = true
+			return mirrorPlanCmd(m.svc, m.mirrorRefQuery, query, m.entityNames().groups)
+		case modalMirrorSelect:
+			groups := make([]awsvc.UserMembership, 0, len(m.mirrorGroups))
+			for _, g := range m.mirrorGroups {
+				if m.mirrorSelected[g.GroupID] {
+					groups = append(groups, g)
+				}
+			}
+			if len(groups) == 0 {
+				m.status = statusMessage{level: statusWarn, text: "No groups selected"}
+				return nil
+			}
+			m.modal = modalNone
+			m.busy = true
+			return mirrorAccessCmd(m.svc, m.mirrorRef, m.mirrorTarget, groups)
+		case modalOffboardConfirm:
+			m.modal = modalNone
+			m.busy = true
+			return executeOffboardCmd(m.svc, m.offboardPlan)
+		case modalMergeConfirm:
+			m.modal = modalNone
+			m.busy = true
+			return mergeGroupsCmd(m.svc, m.mergeSources, m.mergeTarget)
+		case modalMergeDeleteConfirm:
+			m.modal = modalNone
+			m.busy = true
+			return deleteMergedSourcesCmd(m.svc, m.mergeSources, m.report)
+		case modalSyncConfirm:
+			m.modal = modalNone
+			m.busy = true
+			return syncGroupsCmd(m.svc, m.comparison, m.syncFromA, m.syncPrune)
+		case modalTransferConfirm:
+			m.modal = modalNone
+			m.busy = true
+			return transferUsersCmd(m.svc, m.transferUsers, m.group, m.transferTarget, m.transferMove)
+		case modalUndoConfirm:
+			m.modal = modalNone
+			if m.lastInverse == nil {
+				return nil
+			}
+			inverse := m.lastInverse
+			m.lastInverse = nil
+			m.busy = true
+			m.status = statusMessage{level: statusInfo, text: "Undo: " + inverse.label}
+			return inverse.cmd
 		}
 	}
 
@@ -721,6 +1619,122 @@ func (m *model) handleModalKeyMsg(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+func (m *model) openUserSearch() {
+	m.modal = modalUserSearch
+	m.modalList.Title = "Search users"
+	m.modalList.SetItems(nil)
+	m.input.SetValue("")
+	m.input.Placeholder = "User name, display name or email"
+	m.input.Focus()
+	m.status = statusMessage{level: statusInfo, text: "Large directory: type to search users"}
+}
+
+func (m *model) cancelSearch() {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+	}
+}
+
+func (m *model) openUserPaste() {
+	m.modal = modalUserPaste
+	m.area.Reset()
+	m.area.Focus()
+}
+
+func (m *model) markedGroupUsers() []awsvc.GroupUser {
+	users := make([]awsvc.GroupUser, 0, len(m.markedUsers))
+	for _, user := range m.users {
+		if m.markedUsers[user.MembershipID] {
+			users = append(users, user)
+		}
+	}
+	if len(users) == 0 {
+		if user, ok := highlighted(m.list).raw.(awsvc.GroupUser); ok {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+func (m *model) openGroupPicker(purpose groupPickPurpose, title string, excludeIDs ...string) {
+	excluded := make(map[string]bool, len(excludeIDs))
+	for _, id := range excludeIDs {
+		excluded[id] = true
+	}
+
+	items := make([]list.Item, 0, len(m.groups))
+	for _, g := range m.groups {
+		if excluded[g.ID] {
+			continue
+		}
+		items = append(items, uiItem{id: g.ID, title: g.DisplayName, desc: cmp.Or(g.Description, g.ID), raw: g})
+	}
+
+	m.groupPickFor = purpose
+	m.modal = modalGroupPicker
+	m.modalList.Title = title
+	m.modalList.ResetSelected()
+	m.modalList.SetItems(items)
+}
+
+func (m *model) handleGroupPicked(group awsvc.Group) tea.Cmd {
+	switch m.groupPickFor {
+	case pickTransferTarget:
+		m.transferTarget = group
+		m.transferMove = false
+		m.modal = modalTransferConfirm
+	case pickMergeTarget:
+		m.mergeTarget = group
+		m.mergeDelete = false
+		m.modal = modalMergeConfirm
+	case pickCompareTarget:
+		m.modal = modalNone
+		m.screen = screenCompare
+		m.list.Title = fmt.Sprintf("Compare A: %s | B: %s", m.compareSource.DisplayName, group.DisplayName)
+		m.list.ResetSelected()
+		m.setListItems(nil)
+		m.busy = true
+		return compareGroupsCmd(m.svc, m.compareSource, group, m.permissionSets)
+	}
+	return nil
+}
+
+func (m model) groupCountOrUnknown(groupID string) int {
+	if count, ok := m.groupCounts[groupID]; ok {
+		return count
+	}
+	return unknownCount
+}
+
+func (m *model) handleCloneKey(key string) bool {
+	switch {
+	case key == "shift+tab", key == "up":
+		m.cloneFocus = (m.cloneFocus + 2) % 3
+	case m.keys.is(actNextField, key), key == "down":
+		m.cloneFocus = (m.cloneFocus + 1) % 3
+	case m.keys.is(actToggle, key):
+		switch m.cloneFocus {
+		case 1:
+			m.cloneMembers = !m.cloneMembers
+		case 2:
+			m.cloneAssignments = !m.cloneAssignments
+		default:
+			return false
+		}
+		return true
+	default:
+		return false
+	}
+
+	if m.cloneFocus == 0 {
+		m.input.Focus()
+	} else {
+		m.input.Blur()
+	}
+	return true
+}
+
 func (m *model) handleEnter() tea.Cmd {
 	if m.busy {
 		return nil
@@ -733,6 +1747,8 @@ func (m *model) handleEnter() tea.Cmd {
 			return nil
 		}
 		m.region = item.id
+		m.regionFromProfile = false
+		m.context = ""
 		if m.profile == "" {
 			m.screen = screenProfile
 			m.list.Title = "Select profile"
@@ -742,47 +1758,264 @@ func (m *model) handleEnter() tea.Cmd {
 		}
 		m.screen = screenEnsureSession
 		m.busy = true
-		return ensureSessionCmd(m.profile, m.region)
+		return m.ensureSessionCmd()
 
 	case screenProfile:
 		item := selectedItem(m.list)
 		if item.id == "" {
 			return nil
 		}
-		m.profile = item.id
-		m.screen = screenEnsureSession
-		m.busy = true
-		m.status = statusMessage{level: statusInfo, text: "Checking SSO session"}
-		return ensureSessionCmd(m.profile, m.region)
+		profile, _ := item.raw.(profiles.Profile)
+		return m.selectProfile(item.id, profile.IdentityCenterRegion())
 
 	case screenInstance:
 		idx := m.list.Index()
 		if idx < 0 || idx >= len(m.instances) {
 			return nil
 		}
-		m.instance = m.instances[idx]
-		m.svc.SetInstance(m.instance.ARN, m.instance.IdentityStore)
-		m.screen = screenGroups
-		m.configureListForGroups()
-		m.busy = true
-		return loadGroupsCmd(m.svc)
+		return m.useInstance(m.instances[idx])
 
 	case screenGroups:
 		idx := m.list.Index()
 		if idx < 0 || idx >= len(m.groups) {
 			return nil
 		}
-		m.group = m.groups[idx]
-		m.screen = screenGroupDetail
+		return m.openGroupDetail(m.groups[idx])
+
+	case screenEntity:
+		switch raw := highlighted(m.list).raw.(type) {
+		case awsvc.UserMembership:
+			return m.openGroupDetail(m.findGroup(raw.GroupID))
+		case awsvc.Assignment:
+			return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindAccount, Account: awsvc.Account{ID: raw.AccountID, Name: raw.AccountName}})
+		case awsvc.PrincipalAssignment:
+			if raw.PrincipalType == awsvc.KindGroup {
+				return m.openGroupDetail(m.findGroup(raw.PrincipalID))
+			}
+			return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindUser, User: awsvc.User{ID: raw.PrincipalID, DisplayName: raw.PrincipalID}})
+		}
+	}
+
+	return nil
+}
+
+func (m *model) useInstance(instance awsvc.Instance) tea.Cmd {
+	if instance.Region != "" && instance.Region != m.region {
+		m.region = instance.Region
+		m.regionFromProfile = false
+		m.wantInstance = instance.ARN
+		m.wantStore = ""
+		m.screen = screenEnsureSession
+		m.busy = true
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Found Identity Center in %s, connecting", m.region)}
+		return m.ensureSessionCmd()
+	}
+
+	m.instance = instance
+	m.svc.SetInstance(m.instance.ARN, m.instance.IdentityStore)
+	m.screen = screenGroups
+	m.status = statusMessage{level: statusInfo, text: "Loaded Identity Center instance"}
+	m.configureListForGroups()
+	m.busy = true
+	return tea.Batch(loadGroupsCmd(m.svc), m.rememberSession())
+}
+
+func (m *model) selectProfile(name, region string) tea.Cmd {
+	m.profile = name
+	m.context = ""
+	if m.region == "" || m.regionFromProfile {
+		m.region = region
+		m.regionFromProfile = region != ""
+	}
+
+	if m.region == "" {
+		m.screen = screenRegion
+		m.list.Title = "Select region"
+		m.list.ResetSelected()
+		m.setListItems(regionsToItems(m.regions))
+		m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Profile %s has no region; select one", name)}
+		return m.loadProfileRegionsCmd()
+	}
+
+	m.screen = screenEnsureSession
+	m.busy = true
+	m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Checking AWS session for %s in %s", m.profile, m.region)}
+	return m.ensureSessionCmd()
+}
+
+func (m *model) openGroupDetail(group awsvc.Group) tea.Cmd {
+	m.group = group
+	m.screen = screenGroupDetail
+	m.tab = tabUsers
+	m.configureListForUsers()
+	m.setListItems(nil)
+	m.busy = true
+	return tea.Batch(loadGroupUsersCmd(m.svc, m.group.ID), m.rememberSession())
+}
+
+func (m *model) toggleTab() tea.Cmd {
+	if m.tab == tabAccounts {
 		m.tab = tabUsers
 		m.configureListForUsers()
-		m.busy = true
-		return loadGroupUsersCmd(m.svc, m.group.ID)
+		m.setListItems(groupUsersToItems(m.users, m.markedUsers))
+		return m.rememberSession()
+	}
+
+	m.tab = tabAccounts
+	m.configureListForAssignments()
+	m.setListItems(assignmentsToItems(m.assignments))
+	if m.busy {
+		return m.rememberSession()
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.discoverCancel = cancel
+	m.busy = true
+	return tea.Batch(discoverAccountsAssignmentsCmd(ctx, m.svc, m.group.ID), m.rememberSession())
+}
 
+func (m *model) requestUndo() tea.Cmd {
+	if m.lastInverse == nil {
+		m.status = statusMessage{level: statusWarn, text: "Nothing to undo"}
+		return nil
+	}
+	if m.busy {
+		return nil
+	}
+	m.modal = modalUndoConfirm
 	return nil
 }
 
+func (m *model) openAuditLog() tea.Cmd {
+	if m.screen == screenAudit || m.busy {
+		return nil
+	}
+	m.prevScreen = m.screen
+	m.screen = screenAudit
+	m.configureListForAudit()
+	m.setListItems(nil)
+	m.busy = true
+	return loadAuditCmd(m.auditLog)
+}
+
+func (m *model) openPalette() tea.Cmd {
+	if m.svc == nil || m.instance.ARN == "" {
+		m.status = statusMessage{level: statusWarn, text: "Select an Identity Center instance first"}
+		return nil
+	}
+	m.modal = modalPalette
+	m.modalList.Title = "Results"
+	m.modalList.SetItems(nil)
+	m.paletteLocal = nil
+	m.paletteUsers = nil
+	m.input.SetValue("")
+	m.input.Placeholder = "Name, ID, email or ARN of a group, user, account or permission set"
+	m.input.Focus()
+	if m.searchIndex != nil {
+		return nil
+	}
+	m.status = statusMessage{level: statusInfo, text: "Loading groups, accounts and permission sets"}
+	return loadSearchIndexCmd(m.svc)
+}
+
+func (m *model) searchPalette(seq int) tea.Cmd {
+	m.cancelSearch()
+	query := strings.TrimSpace(m.input.Value())
+	m.paletteLocal = nil
+	m.paletteUsers = nil
+	if m.searchIndex != nil {
+		m.paletteLocal = m.searchIndex.Search(query)
+	}
+	m.setPaletteItems()
+	if len(query) < 2 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.searchCancel = cancel
+	return searchPaletteUsersCmd(ctx, m.svc, query, seq)
+}
+
+func (m *model) setPaletteItems() {
+	results := awsvc.RankSearchResults(append(append([]awsvc.SearchResult(nil), m.paletteLocal...), m.paletteUsers...), paletteLimit)
+	m.modalList.SetItems(searchResultsToItems(results))
+	m.modalList.Select(0)
+}
+
+func (m *model) openSearchResult(result awsvc.SearchResult) tea.Cmd {
+	names := m.entityNames()
+	switch result.Kind {
+	case awsvc.KindGroup:
+		return m.openGroupDetail(result.Group)
+	case awsvc.KindUser:
+		return m.openEntity("User "+result.User.DisplayName, loadUserEntityCmd(m.svc, result.User.ID, names))
+	case awsvc.KindAccount:
+		title := "Account " + result.Account.ID
+		if result.Account.Name != "" {
+			title = fmt.Sprintf("Account %s (%s)", result.Account.Name, result.Account.ID)
+		}
+		return m.openEntity(title, loadAccountEntityCmd(m.svc, title, result.Account.ID, names))
+	default:
+		title := "Permission set " + result.PermissionSet.Name
+		return m.openEntity(title, loadPermissionSetEntityCmd(m.svc, title, result.PermissionSet.ARN, names))
+	}
+}
+
+func (m *model) openEntity(title string, load tea.Cmd) tea.Cmd {
+	if m.screen != screenEntity && m.screen != screenAudit {
+		m.entityReturn = m.screen
+	}
+	m.screen = screenEntity
+	m.entityTitle = title
+	m.entityItems = nil
+	m.entityLoad = load
+	m.list.Title = title
+	m.list.ResetSelected()
+	m.list.SetShowTitle(true)
+	m.setListItems(nil)
+	m.busy = true
+	return load
+}
+
+func (m *model) findGroup(groupID string) awsvc.Group {
+	for _, g := range m.groups {
+		if g.ID == groupID {
+			return g
+		}
+	}
+	if m.searchIndex != nil {
+		for _, g := range m.searchIndex.Groups {
+			if g.ID == groupID {
+				return g
+			}
+		}
+	}
+	return awsvc.Group{ID: groupID, DisplayName: groupID}
+}
+
+type entityNames struct {
+	groups         map[string]string
+	accounts       map[string]string
+	permissionSets map[string]string
+}
+
+func (m *model) entityNames() entityNames {
+	names := entityNames{groups: map[string]string{}, accounts: map[string]string{}, permissionSets: map[string]string{}}
+	groups, accounts, permissionSets := m.groups, m.accounts, m.permissionSets
+	if m.searchIndex != nil {
+		groups, accounts, permissionSets = m.searchIndex.Groups, m.searchIndex.Accounts, m.searchIndex.PermissionSets
+	}
+	for _, g := range groups {
+		names.groups[g.ID] = g.DisplayName
+	}
+	for _, a := range accounts {
+		names.accounts[a.ID] = a.Name
+	}
+	for _, ps := range permissionSets {
+		names.permissionSets[ps.ARN] = ps.Name
+	}
+	return names
+}
+
 func (m *model) handleEsc() tea.Cmd {
 	if m.busy && m.screen == screenGroupDetail && m.tab == tabAccounts && m.discoverCancel != nil {
 		m.discoverCancel()
@@ -792,16 +2025,68 @@ func (m *model) handleEsc() tea.Cmd {
 		return nil
 	}
 
+	if m.screen == screenAudit {
+		return m.restoreScreen(m.prevScreen)
+	}
+
+	if m.screen == screenCompare {
+		return m.restoreScreen(screenGroups)
+	}
+
+	if m.screen == screenEntity {
+		return m.restoreScreen(m.entityReturn)
+	}
+
 	if m.screen == screenGroupDetail {
 		m.screen = screenGroups
 		m.configureListForGroups()
-		m.setListItems(groupsToItems(m.groups, m.group.ID, m.groupCounts[m.group.ID]))
+		m.setListItems(groupsToItems(m.groups, m.group.ID, m.groupCounts[m.group.ID], m.markedGroups))
 		return nil
 	}
 
 	return nil
 }
 
+func (m *model) restoreScreen(target screen) tea.Cmd {
+	m.screen = target
+	switch target {
+	case screenRegion:
+		m.list.Title = "Select region"
+		m.list.ResetSelected()
+		m.setListItems(regionsToItems(m.regions))
+	case screenProfile:
+		m.list.Title = "Select profile"
+		m.list.ResetSelected()
+		m.setListItems(nil)
+		m.busy = true
+		return loadProfilesCmd()
+	case screenInstance:
+		m.list.Title = "Select Identity Center instance"
+		m.list.ResetSelected()
+		m.setListItems(instancesToItems(m.instances))
+	case screenGroups:
+		m.configureListForGroups()
+		m.setListItems(groupsToItems(m.groups, m.group.ID, m.groupCounts[m.group.ID], m.markedGroups))
+	case screenEntity:
+		m.list.Title = m.entityTitle
+		m.list.ResetSelected()
+		m.setListItems(m.entityItems)
+	case screenCompare:
+		m.list.Title = fmt.Sprintf("Compare A: %s | B: %s", m.comparison.A.DisplayName, m.comparison.B.DisplayName)
+		m.list.ResetSelected()
+		m.setListItems(comparisonToItems(m.comparison, m.accounts))
+	case screenGroupDetail:
+		if m.tab == tabUsers {
+			m.configureListForUsers()
+			m.setListItems(groupUsersToItems(m.users, m.markedUsers))
+		} else {
+			m.configureListForAssignments()
+			m.setListItems(assignmentsToItems(m.assignments))
+		}
+	}
+	return nil
+}
+
 func (m *model) refreshCurrentScreen() tea.Cmd {
 	if m.busy {
 		return nil
@@ -811,6 +2096,18 @@ func (m *model) refreshCurrentScreen() tea.Cmd {
 	case screenGroups:
 		m.busy = true
 		return loadGroupsCmd(m.svc)
+	case screenAudit:
+		m.busy = true
+		return loadAuditCmd(m.auditLog)
+	case screenEntity:
+		if m.entityLoad == nil {
+			return nil
+		}
+		m.busy = true
+		return m.entityLoad
+	case screenCompare:
+		m.busy = true
+		return compareGroupsCmd(m.svc, m.comparison.A, m.comparison.B, m.permissionSets)
 	case screenGroupDetail:
 		if m.tab == tabUsers {
 			m.busy = true
@@ -857,7 +2154,11 @@ func (m model) headerText() string {
 	if region == "" {
 		region = "-"
 	}
-	return fmt.Sprintf("aws-groups-manager | profile: %s | region: %s | instance: %s", profile, region, instance)
+	header := fmt.Sprintf("aws-groups-manager | profile: %s | region: %s | instance: %s", profile, region, instance)
+	if m.context != "" {
+		header = fmt.Sprintf("aws-groups-manager | context: %s | profile: %s | region: %s | instance: %s", m.context, profile, region, instance)
+	}
+	return header
 }
 
 func (m model) renderStatus(base lipgloss.Style) string {
@@ -894,23 +2195,35 @@ func (m model) renderTabs() string {
 }
 
 func (m model) footerText() string {
-	items := []string{"^G Help", "^R Refresh", "^F Search"}
+	hints := []hint{{actHelp, "Help"}, {actRefresh, "Refresh"}, {actFilter, "Filter"}, {actFind, "Find"}, {actAuditLog, "Audit Log"}}
 
 	if m.screen == screenGroups {
-		items = append(items, "^N Create Group", "^D Delete Group")
+		hints = append(hints, hint{actMark, "Mark"}, hint{actCreateGroup, "Create Group"}, hint{actCloneGroup, "Clone Group"}, hint{actCompare, "Compare"},
+			hint{actMerge, "Merge Marked"}, hint{actMirrorAccess, "Mirror Access"}, hint{actOffboard, "Offboard"}, hint{actDeleteGroup, "Delete Group"})
+	}
+
+	if m.screen == screenCompare {
+		hints = append(hints, hint{actSync, "Sync"})
 	}
 
 	if m.screen == screenGroupDetail {
 		if m.tab == tabUsers {
-			items = append(items, "^A Add User", "^X Remove User")
+			hints = append(hints, hint{actMark, "Mark"}, hint{actAdd, "Add User"}, hint{actPasteUsers, "Paste Users"}, hint{actCopyMove, "Copy/Move"},
+				hint{actOffboard, "Offboard"}, hint{actRemove, "Remove User"})
 		} else {
-			items = append(items, "^A Add Assignment", "^X Remove Assignment")
+			hints = append(hints, hint{actAdd, "Add Assignment"}, hint{actRemove, "Remove Assignment"})
 		}
 	}
 
-	items = append(items, "Enter Select", "Esc Back", "^C Quit")
+	if m.lastInverse != nil {
+		hints = append(hints, hint{actUndo, "Undo"})
+	}
+
+	hints = append(hints, hint{actCommand, "Command"})
+	items := append(m.keys.render(hints), "Enter Select", "Esc Back")
+	items = append(items, m.keys.render([]hint{{actQuit, "Quit"}})...)
 	if m.lastErr != nil {
-		items = append([]string{"^E Error"}, items...)
+		items = append(m.keys.render([]hint{{actErrorDetails, "Error"}}), items...)
 	}
 
 	return strings.Join(items, "  ")
@@ -921,63 +2234,270 @@ func (m model) renderModal() string {
 	case modalHelp:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Help") + "\n\n" +
-				"Navigation: arrows, Enter, Esc, Tab/Shift+Tab\n" +
-				"Actions: Ctrl-only shortcuts shown in footer\n\n" +
-				m.styles.ModalHint.Render("Enter/Esc to close"),
+				m.keys.helpText() + "\n\n" +
+				m.renderHint("Enter/Esc to close"),
 		)
 	case modalErrorDetails:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Error Details") + "\n\n" +
 				m.lastDetails + "\n\n" +
-				m.styles.ModalHint.Render("Enter/Esc to close"),
+				m.renderHint("Enter/Esc to close"),
+		)
+	case modalBlockingError:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render(m.blockErr.title) + "\n\n" +
+				m.blockErr.message + "\n\n" +
+				"Next step: " + m.blockErr.details + "\n\n" +
+				m.renderHint("Press Enter"),
+		)
+	case modalGroupCreateInput:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Create Group") + "\n\n" +
+				m.input.View() + "\n\n" +
+				m.renderHint("Enter create | Esc cancel"),
+		)
+	case modalGroupDeleteConfirm:
+		groupName := "selected group"
+		if idx := m.list.Index(); idx >= 0 && idx < len(m.groups) {
+			groupName = m.groups[idx].DisplayName
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Delete Group") + "\n\n" +
+				fmt.Sprintf("Delete group %q?", groupName) + "\n\n" +
+				m.renderHint("Enter confirm | Esc cancel"),
+		)
+	case modalMirrorInput:
+		step := "Step 1/2: user whose access should be copied"
+		if m.mirrorStep == 1 {
+			step = fmt.Sprintf("Step 2/2: user who receives the access of %q", m.mirrorRefQuery)
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Mirror Access") + "\n\n" +
+				step + "\n\n" +
+				m.input.View() + "\n\n" +
+				m.renderHint("Enter continue | Esc cancel"),
+		)
+	case modalMirrorSelect:
+		selected := 0
+		for _, g := range m.mirrorGroups {
+			if m.mirrorSelected[g.GroupID] {
+				selected++
+			}
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render(m.modalList.Title) + "\n\n" +
+				fmt.Sprintf("%d of %d groups selected (%d already shared)", selected, len(m.mirrorGroups), m.mirrorExisting) + "\n\n" +
+				m.modalList.View() + "\n\n" +
+				m.renderHint("Space toggle | Enter grant | Esc cancel"),
+		)
+	case modalOffboardInput:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Offboard User") + "\n\n" +
+				m.input.View() + "\n\n" +
+				m.renderHint("Enter review | Esc cancel"),
+		)
+	case modalOffboardConfirm:
+		plan := m.offboardPlan
+		lines := make([]string, 0, len(plan.Memberships)+len(plan.Assignments))
+		for _, membership := range plan.Memberships {
+			lines = append(lines, "group: "+membership.GroupName)
+		}
+		for _, a := range plan.Assignments {
+			lines = append(lines, fmt.Sprintf("assignment: %s on %s", shortARN(a.PermissionSetARN), a.AccountID))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "nothing to remove")
+		}
+		if len(plan.Inherited) > 0 {
+			lines = append(lines, "", fmt.Sprintf("Inherited from groups (ends with the memberships): %d", len(plan.Inherited)))
+			groupNames := make(map[string]string, len(plan.Memberships))
+			for _, membership := range plan.Memberships {
+				groupNames[membership.GroupID] = membership.GroupName
+			}
+			for _, a := range plan.Inherited {
+				lines = append(lines, fmt.Sprintf("via %s: %s on %s", cmp.Or(groupNames[a.InheritedFrom], a.InheritedFrom), shortARN(a.PermissionSetARN), a.AccountID))
+			}
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Offboard "+plan.User.DisplayName) + "\n\n" +
+				fmt.Sprintf("%s | %s | %s", plan.User.UserName, cmp.Or(plan.User.Email, "-"), plan.User.ID) + "\n\n" +
+				fmt.Sprintf("Remove %d group memberships and %d direct assignments:", len(plan.Memberships), len(plan.Assignments)) + "\n" +
+				strings.Join(lines, "\n") + "\n\n" +
+				m.renderHint("Enter offboard and write signed report | Esc cancel"),
+		)
+	case modalMergeConfirm:
+		names := make([]string, 0, len(m.mergeSources))
+		for _, g := range m.mergeSources {
+			names = append(names, g.DisplayName)
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Merge Groups") + "\n\n" +
+				fmt.Sprintf("Sources: %s\nTarget: %s", strings.Join(names, ", "), m.mergeTarget.DisplayName) + "\n\n" +
+				"Members and assignments of every source are added to the target.\n\n" +
+				m.renderCheckbox("Offer to delete source groups after a clean merge", m.mergeDelete, true) + "\n\n" +
+				m.renderHint("Space toggle | Enter merge | Esc cancel"),
+		)
+	case modalMergeDeleteConfirm:
+		names := make([]string, 0, len(m.mergeSources))
+		for _, g := range m.mergeSources {
+			names = append(names, g.DisplayName)
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Delete Merged Source Groups") + "\n\n" +
+				m.report.Summary() + "\n\n" +
+				renderReportLines(m.report, max(5, m.height/3)) + "\n\n" +
+				fmt.Sprintf("Delete %d source groups: %s?", len(names), strings.Join(names, ", ")) + "\n\n" +
+				m.renderHint("Enter delete | Esc keep sources"),
+		)
+	case modalSyncConfirm:
+		from, to := m.comparison.A, m.comparison.B
+		if !m.syncFromA {
+			from, to = to, from
+		}
+		direction := fmt.Sprintf("Direction: %s → %s", from.DisplayName, to.DisplayName)
+		if m.syncFocus == 0 {
+			direction = m.styles.InlineHighlight.Render("▸ " + direction)
+		} else {
+			direction = "  " + direction
+		}
+		removals := ""
+		if m.syncPrune {
+			lines := m.comparison.SyncRemovals(m.syncFromA)
+			if len(lines) == 0 {
+				lines = []string{"nothing to remove"}
+			}
+			limit := max(5, m.height/3)
+			if len(lines) > limit {
+				lines = append(lines[:limit:limit], fmt.Sprintf("... and %d more", len(lines)-limit))
+			}
+			removals = fmt.Sprintf("Will remove from %s:", to.DisplayName) + "\n" + strings.Join(lines, "\n") + "\n\n"
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Sync Groups") + "\n\n" +
+				direction + "\n" +
+				m.renderCheckbox(fmt.Sprintf("Remove members and assignments only in %s", to.DisplayName), m.syncPrune, m.syncFocus == 1) + "\n\n" +
+				removals +
+				m.renderHint("Tab next field | Space toggle | Enter sync | Esc cancel"),
+		)
+	case modalTransferConfirm:
+		names := make([]string, 0, len(m.transferUsers))
+		for _, user := range m.transferUsers {
+			names = append(names, user.DisplayName)
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Copy or Move Users") + "\n\n" +
+				fmt.Sprintf("From: %s\nTo: %s\nUsers: %s", m.group.DisplayName, m.transferTarget.DisplayName, strings.Join(names, ", ")) + "\n\n" +
+				m.renderCheckbox("Copy (keep in source group)", !m.transferMove, !m.transferMove) + "\n" +
+				m.renderCheckbox("Move (remove from source group)", m.transferMove, m.transferMove) + "\n\n" +
+				m.renderHint("Tab switch mode | Enter confirm | Esc cancel"),
+		)
+	case modalCommand:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Command") + "\n\n" +
+				":" + m.input.View() + "\n\n" +
+				strings.Join(m.commandSuggestions, "\n") + "\n\n" +
+				m.renderHint("Tab complete | Enter run | Esc cancel"),
 		)
-	case modalBlockingError:
+	case modalPalette:
 		return m.styles.Modal.Render(
-			m.styles.ModalTitle.Render(m.blockErr.title) + "\n\n" +
-				m.blockErr.message + "\n\n" +
-				"Next step: " + m.blockErr.details + "\n\n" +
-				m.styles.ModalHint.Render("Press Enter"),
+			m.styles.ModalTitle.Render("Find") + "\n\n" +
+				m.input.View() + "\n\n" +
+				m.modalList.View() + "\n\n" +
+				m.renderHint("Type to search | Up/Down choose | Enter open | Esc cancel"),
 		)
-	case modalGroupCreateInput:
+	case modalUserSearch:
 		return m.styles.Modal.Render(
-			m.styles.ModalTitle.Render("Create Group") + "\n\n" +
+			m.styles.ModalTitle.Render("Add User") + "\n\n" +
 				m.input.View() + "\n\n" +
-				m.styles.ModalHint.Render("Enter create | Esc cancel"),
+				m.modalList.View() + "\n\n" +
+				m.renderHint("Type to search | Up/Down choose | Enter add | "+m.keys.label(actPickerPaste)+" paste a list | Esc cancel"),
 		)
-	case modalGroupDeleteConfirm:
-		groupName := "selected group"
-		if idx := m.list.Index(); idx >= 0 && idx < len(m.groups) {
-			groupName = m.groups[idx].DisplayName
+	case modalUserPaste:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Add Users From List") + "\n\n" +
+				"Paste user names or emails, one per line.\n\n" +
+				m.area.View() + "\n\n" +
+				m.renderHint(m.keys.label(actSubmit)+" resolve | Esc cancel"),
+		)
+	case modalUserPasteConfirm:
+		lines := make([]string, 0, len(m.pasteUnresolved))
+		for _, u := range m.pasteUnresolved {
+			lines = append(lines, fmt.Sprintf("  %s: %v", u.Query, u.Err))
+		}
+		unresolved := "All lines resolved."
+		if len(lines) > 0 {
+			unresolved = fmt.Sprintf("Not resolved (%d):\n%s", len(lines), strings.Join(lines, "\n"))
+		}
+		hint := "Enter add | Esc cancel"
+		if len(m.pasteResolved) == 0 {
+			hint = "Enter/Esc close"
 		}
 		return m.styles.Modal.Render(
-			m.styles.ModalTitle.Render("Delete Group") + "\n\n" +
-				fmt.Sprintf("Delete group %q?", groupName) + "\n\n" +
-				m.styles.ModalHint.Render("Enter confirm | Esc cancel"),
+			m.styles.ModalTitle.Render("Add Users From List") + "\n\n" +
+				fmt.Sprintf("Add %d users to %s (%d already members skipped).", len(m.pasteResolved), m.group.DisplayName, m.pasteMembers) + "\n\n" +
+				unresolved + "\n\n" +
+				m.renderHint(hint),
+		)
+	case modalUserPicker:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render(m.modalList.Title) + "\n\n" +
+				m.modalList.View() + "\n\n" +
+				m.renderHint("Enter select | "+m.keys.label(actPickerPaste)+" paste a list | Esc cancel"),
 		)
-	case modalUserPicker, modalAccountPicker, modalPermissionSetPicker:
+	case modalAccountPicker, modalPermissionSetPicker, modalGroupPicker:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render(m.modalList.Title) + "\n\n" +
 				m.modalList.View() + "\n\n" +
-				m.styles.ModalHint.Render("Enter select | Esc cancel"),
+				m.renderHint("Enter select | Esc cancel"),
 		)
 	case modalUserRemoveConfirm:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Remove User") + "\n\n" +
 				fmt.Sprintf("Remove %q from this group?", m.pendingRemoveUser.DisplayName) + "\n\n" +
-				m.styles.ModalHint.Render("Enter confirm | Esc cancel"),
+				m.renderHint("Enter confirm | Esc cancel"),
 		)
 	case modalAssignmentRemoveConfirm:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Remove Assignment") + "\n\n" +
 				fmt.Sprintf("Remove %s on %s?", m.pendingRemoveAssign.PermissionSetName, m.pendingRemoveAssign.AccountID) + "\n\n" +
-				m.styles.ModalHint.Render("Enter confirm | Esc cancel"),
+				m.renderHint("Enter confirm | Esc cancel"),
 		)
 	case modalManualAccountInput:
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Manual Account ID") + "\n\n" +
 				m.input.View() + "\n\n" +
 				"Organizations access is unavailable. Enter account ID directly.\n\n" +
-				m.styles.ModalHint.Render("Enter continue | Esc cancel"),
+				m.renderHint("Enter continue | Esc cancel"),
+		)
+	case modalGroupClone:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Clone Group") + "\n\n" +
+				fmt.Sprintf("Source: %s", m.cloneSource.DisplayName) + "\n\n" +
+				m.input.View() + "\n\n" +
+				m.renderCheckbox("Copy members", m.cloneMembers, m.cloneFocus == 1) + "\n" +
+				m.renderCheckbox("Copy account assignments", m.cloneAssignments, m.cloneFocus == 2) + "\n\n" +
+				m.renderHint("Tab next field | Space toggle | Enter clone | Esc cancel"),
+		)
+	case modalLogin:
+		return m.renderLoginModal()
+	case modalMFAInput:
+		return m.renderMFAModal()
+	case modalReport:
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render(m.reportTitle) + "\n\n" +
+				m.report.Summary() + "\n\n" +
+				renderReportLines(m.report, max(5, m.height/2)) + "\n\n" +
+				m.renderHint("Enter/Esc to close"),
+		)
+	case modalUndoConfirm:
+		label := "nothing"
+		if m.lastInverse != nil {
+			label = m.lastInverse.label
+		}
+		return m.styles.Modal.Render(
+			m.styles.ModalTitle.Render("Undo Last Change") + "\n\n" +
+				label + "?\n\n" +
+				m.renderHint("Enter confirm | Esc cancel"),
 		)
 	case modalAssignmentCreateConfirm:
 		account := m.selectedManualAccount
@@ -987,19 +2507,46 @@ func (m model) renderModal() string {
 		return m.styles.Modal.Render(
 			m.styles.ModalTitle.Render("Create Assignment") + "\n\n" +
 				fmt.Sprintf("Account: %s\nPermission set: %s", account, m.selectedPermissionSet.Name) + "\n\n" +
-				m.styles.ModalHint.Render("Enter confirm | Esc cancel"),
+				m.renderHint("Enter confirm | Esc cancel"),
 		)
 	}
 
 	return ""
 }
 
+func (m model) renderCheckbox(label string, checked, focused bool) string {
+	box := "[ ] "
+	if checked {
+		box = "[x] "
+	}
+	if focused {
+		return m.styles.InlineHighlight.Render("▸ " + box + label)
+	}
+	return "  " + box + label
+}
+
+func renderReportLines(report awsvc.ChangeReport, limit int) string {
+	lines := make([]string, 0, len(report.Results))
+	for _, res := range report.Results {
+		outcome := "ok"
+		if res.Err != nil {
+			outcome = "FAILED: " + res.Err.Error()
+		}
+		lines = append(lines, fmt.Sprintf("%s %s - %s", res.Action, res.Target, outcome))
+	}
+	if len(lines) > limit {
+		hidden := len(lines) - limit
+		lines = append(lines[:limit], fmt.Sprintf("... %d more (see audit log)", hidden))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m model) modalUsesList() bool {
-	return m.modal == modalUserPicker || m.modal == modalAccountPicker || m.modal == modalPermissionSetPicker
+	return m.modal == modalUserPicker || m.modal == modalAccountPicker || m.modal == modalPermissionSetPicker || m.modal == modalGroupPicker || m.modal == modalMirrorSelect
 }
 
 func (m model) modalUsesInput() bool {
-	return m.modal == modalGroupCreateInput || m.modal == modalManualAccountInput
+	return m.modal == modalGroupCreateInput || m.modal == modalManualAccountInput || m.modal == modalGroupClone || m.modal == modalOffboardInput || m.modal == modalMirrorInput
 }
 
 func (m *model) configureListForGroups() {
@@ -1020,6 +2567,12 @@ func (m *model) configureListForAssignments() {
 	m.list.SetShowTitle(true)
 }
 
+func (m *model) configureListForAudit() {
+	m.list.Title = "Audit Log"
+	m.list.ResetSelected()
+	m.list.SetShowTitle(true)
+}
+
 func (m *model) setListItems(items []list.Item) {
 	currentIndex := m.list.Index()
 	m.list.SetItems(items)
@@ -1041,22 +2594,30 @@ func (m model) currentGroupID() string {
 }
 
 type profilesMsg struct {
-	profiles []string
+	profiles []profiles.Profile
 	err      error
 }
 
 func loadProfilesCmd() tea.Cmd {
 	return func() tea.Msg {
-		profiles, err := loadProfiles()
-		return profilesMsg{profiles: profiles, err: err}
+		list, err := loadProfiles()
+		return profilesMsg{profiles: list, err: err}
 	}
 }
 
-func ensureSessionCmd(profile, region string) tea.Cmd {
+func (m model) loadProfileRegionsCmd() tea.Cmd {
+	svc := awsvc.NewService(m.profile, awsvc.AllRegions)
+	svc.SetOptions(m.startCfg.Service)
+	return loadRegionsCmd(svc)
+}
+
+func loadRegionsCmd(svc *awsvc.Service) tea.Cmd {
 	return func() tea.Msg {
-		svc := awsvc.NewService(profile, region)
-		instances, err := svc.EnsureSession(context.Background())
-		return ensureSessionMsg{svc: svc, instances: instances, err: err}
+		regions, err := svc.EnabledRegions(context.Background())
+		if err != nil || len(regions) == 0 {
+			return nil
+		}
+		return regionsMsg{regions: regions}
 	}
 }
 
@@ -1076,7 +2637,7 @@ func loadGroupCountCmd(svc *awsvc.Service, groupID string) tea.Cmd {
 
 func createGroupCmd(svc *awsvc.Service, name string) tea.Cmd {
 	return func() tea.Msg {
-		err := svc.CreateGroup(context.Background(), name)
+		_, err := svc.CreateGroup(context.Background(), name)
 		return mutationMsg{operation: "Create group", err: err}
 	}
 }
@@ -1097,22 +2658,356 @@ func loadGroupUsersCmd(svc *awsvc.Service, groupID string) tea.Cmd {
 
 func loadAllUsersCmd(svc *awsvc.Service) tea.Cmd {
 	return func() tea.Msg {
-		users, err := svc.ListUsers(context.Background())
-		return allUsersMsg{users: users, err: err}
+		users, complete, err := svc.ListUsersLimit(context.Background(), smallDirectoryUsers)
+		return allUsersMsg{users: users, complete: complete, err: err}
+	}
+}
+
+func loadSearchIndexCmd(svc *awsvc.Service) tea.Cmd {
+	return func() tea.Msg {
+		index, err := svc.LoadSearchIndex(context.Background())
+		return searchIndexMsg{index: index, err: err}
+	}
+}
+
+func searchPaletteUsersCmd(ctx context.Context, svc *awsvc.Service, query string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		search, users, err := svc.SearchUsers(ctx, query)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return paletteUsersMsg{ctx: ctx, seq: seq, query: query, search: search, users: users, err: err}
+	}
+}
+
+func nextPaletteUsersCmd(ctx context.Context, search *awsvc.UserSearch, query string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		users, err := search.Next(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return paletteUsersMsg{ctx: ctx, seq: seq, query: query, search: search, users: users, err: err}
+	}
+}
+
+func loadUserEntityCmd(svc *awsvc.Service, query string, names entityNames) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		user, err := svc.FindUser(ctx, query)
+		if err != nil {
+			return entityMsg{err: err}
+		}
+		title := fmt.Sprintf("User %s (%s)", user.DisplayName, cmp.Or(user.Email, user.UserName))
+
+		memberships, err := svc.ListUserMemberships(ctx, user.ID, names.groups)
+		if err != nil {
+			return entityMsg{title: title, err: err}
+		}
+		direct, inherited, err := svc.ListUserAccess(ctx, user.ID)
+		if err != nil {
+			return entityMsg{title: title, err: err}
+		}
+
+		groupNames := make(map[string]string, len(memberships))
+		items := make([]list.Item, 0, len(memberships)+len(direct)+len(inherited))
+		for _, membership := range memberships {
+			groupNames[membership.GroupID] = membership.GroupName
+			items = append(items, uiItem{id: membership.GroupID, title: "group: " + membership.GroupName, desc: membership.GroupID, raw: membership})
+		}
+		for _, a := range append(direct, inherited...) {
+			a.AccountName = names.accounts[a.AccountID]
+			a.PermissionSetName = names.permissionSets[a.PermissionSetARN]
+			source := "direct"
+			if a.InheritedFrom != "" {
+				source = "via " + cmp.Or(groupNames[a.InheritedFrom], names.groups[a.InheritedFrom], a.InheritedFrom)
+			}
+			items = append(items, uiItem{
+				id:    a.InheritedFrom + "|" + a.AccountID + "|" + a.PermissionSetARN,
+				title: fmt.Sprintf("%s: %s on %s", source, cmp.Or(a.PermissionSetName, shortARN(a.PermissionSetARN)), cmp.Or(a.AccountName, a.AccountID)),
+				desc:  a.AccountID,
+				raw:   a,
+			})
+		}
+		return entityMsg{title: title, items: items}
+	}
+}
+
+func loadAccountEntityCmd(svc *awsvc.Service, title, accountID string, names entityNames) tea.Cmd {
+	return func() tea.Msg {
+		access, err := svc.ListAccountAccess(context.Background(), accountID)
+		if err != nil {
+			return entityMsg{title: title, err: err}
+		}
+		return entityMsg{title: title, items: accessToItems(svc, access, names, false)}
+	}
+}
+
+func loadPermissionSetEntityCmd(svc *awsvc.Service, title, permissionSetARN string, names entityNames) tea.Cmd {
+	return func() tea.Msg {
+		access, err := svc.ListPermissionSetAccess(context.Background(), permissionSetARN)
+		if err != nil {
+			return entityMsg{title: title, err: err}
+		}
+		return entityMsg{title: title, items: accessToItems(svc, access, names, true)}
+	}
+}
+
+func accessToItems(svc *awsvc.Service, access []awsvc.PrincipalAssignment, names entityNames, byAccount bool) []list.Item {
+	userNames := make(map[string]string)
+	items := make([]list.Item, 0, len(access))
+	for _, a := range access {
+		principal := names.groups[a.PrincipalID]
+		if a.PrincipalType == awsvc.KindUser {
+			name, ok := userNames[a.PrincipalID]
+			if !ok {
+				if user, err := svc.GetUser(context.Background(), a.PrincipalID); err == nil {
+					name = user.DisplayName
+				}
+				userNames[a.PrincipalID] = name
+			}
+			principal = name
+		}
+
+		target := cmp.Or(names.permissionSets[a.PermissionSetARN], shortARN(a.PermissionSetARN))
+		if byAccount {
+			target = cmp.Or(names.accounts[a.AccountID], a.AccountID)
+		}
+		items = append(items, uiItem{
+			id:    a.AccountID + "|" + a.PermissionSetARN + "|" + a.PrincipalID,
+			title: fmt.Sprintf("%s -> %s: %s", target, a.PrincipalType, cmp.Or(principal, a.PrincipalID)),
+			desc:  a.PrincipalID,
+			raw:   a,
+		})
+	}
+	return items
+}
+
+func searchUsersCmd(ctx context.Context, svc *awsvc.Service, query string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		search, users, err := svc.SearchUsers(ctx, query)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return userSearchMsg{ctx: ctx, seq: seq, search: search, users: users, err: err}
+	}
+}
+
+func nextSearchUsersCmd(ctx context.Context, search *awsvc.UserSearch, seq int) tea.Cmd {
+	return func() tea.Msg {
+		users, err := search.Next(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return userSearchMsg{ctx: ctx, seq: seq, search: search, users: users, err: err}
+	}
+}
+
+func addUserCmd(svc *awsvc.Service, group awsvc.Group, user awsvc.GroupUser) tea.Cmd {
+	return func() tea.Msg {
+		membershipID, err := svc.AddUserToGroup(context.Background(), group.ID, user.UserID)
+		user.MembershipID = membershipID
+		return mutationMsg{
+			operation: "Add user",
+			err:       err,
+			inverse: &inverseOp{
+				label: fmt.Sprintf("Remove %s from %s", cmp.Or(user.DisplayName, user.UserID), group.DisplayName),
+				cmd:   removeUserCmd(svc, group, user),
+			},
+		}
+	}
+}
+
+func removeUserCmd(svc *awsvc.Service, group awsvc.Group, user awsvc.GroupUser) tea.Cmd {
+	return func() tea.Msg {
+		err := svc.RemoveUserFromGroup(context.Background(), group.ID, user.UserID, user.MembershipID)
+		return mutationMsg{
+			operation: "Remove user",
+			err:       err,
+			inverse: &inverseOp{
+				label: fmt.Sprintf("Re-add %s to %s", cmp.Or(user.DisplayName, user.UserID), group.DisplayName),
+				cmd:   addUserCmd(svc, group, user),
+			},
+		}
+	}
+}
+
+func cloneGroupCmd(svc *awsvc.Service, sourceID, name string, opts awsvc.CloneOptions) tea.Cmd {
+	return func() tea.Msg {
+		_, report, err := svc.CloneGroup(context.Background(), sourceID, name, opts)
+		return bulkMsg{operation: "Clone group", report: report, err: err}
+	}
+}
+
+func transferUsersCmd(svc *awsvc.Service, users []awsvc.GroupUser, source, target awsvc.Group, move bool) tea.Cmd {
+	return func() tea.Msg {
+		operation := "Copy users to " + target.DisplayName
+		if move {
+			operation = "Move users to " + target.DisplayName
+		}
+		report := svc.TransferUsers(context.Background(), users, source.ID, target.ID, move)
+		return bulkMsg{operation: operation, report: report}
+	}
+}
+
+func planOffboardCmd(svc *awsvc.Service, query string, byID bool, groupNames map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var user awsvc.User
+		var err error
+		if byID {
+			user, err = svc.GetUser(ctx, query)
+		} else {
+			user, err = svc.FindUser(ctx, query)
+		}
+		if err != nil {
+			return offboardPlanMsg{err: fmt.Errorf("resolve user %q: %w", query, err)}
+		}
+
+		plan, err := svc.PlanOffboard(ctx, user, groupNames)
+		return offboardPlanMsg{plan: plan, err: err}
+	}
+}
+
+func resolvePastedUsersCmd(svc *awsvc.Service, queries []string, members []awsvc.GroupUser) tea.Cmd {
+	return func() tea.Msg {
+		resolved, unresolved := svc.ResolveUsers(context.Background(), queries)
+
+		inGroup := make(map[string]bool, len(members))
+		for _, member := range members {
+			inGroup[member.UserID] = true
+		}
+		toAdd := make([]awsvc.User, 0, len(resolved))
+		existing := 0
+		for _, user := range resolved {
+			if inGroup[user.ID] {
+				existing++
+				continue
+			}
+			toAdd = append(toAdd, user)
+		}
+
+		return pasteResolvedMsg{resolved: toAdd, unresolved: unresolved, members: existing}
+	}
+}
+
+func addPastedUsersCmd(svc *awsvc.Service, group awsvc.Group, users []awsvc.User) tea.Cmd {
+	return func() tea.Msg {
+		report := svc.AddUsersToGroup(context.Background(), group.ID, users)
+		return bulkMsg{operation: fmt.Sprintf("Add %d users to %s", len(users), group.DisplayName), report: report}
+	}
+}
+
+func mirrorPlanCmd(svc *awsvc.Service, refQuery, targetQuery string, groupNames map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		ref, err := svc.FindUser(ctx, refQuery)
+		if err != nil {
+			return mirrorPlanMsg{err: fmt.Errorf("resolve reference user %q: %w", refQuery, err)}
+		}
+		target, err := svc.FindUser(ctx, targetQuery)
+		if err != nil {
+			return mirrorPlanMsg{err: fmt.Errorf("resolve new user %q: %w", targetQuery, err)}
+		}
+		if ref.ID == target.ID {
+			return mirrorPlanMsg{err: fmt.Errorf("reference and new user are the same")}
+		}
+
+		refGroups, err := svc.ListUserMemberships(ctx, ref.ID, groupNames)
+		if err != nil {
+			return mirrorPlanMsg{err: err}
+		}
+		targetGroups, err := svc.ListUserMemberships(ctx, target.ID, groupNames)
+		if err != nil {
+			return mirrorPlanMsg{err: err}
+		}
+
+		have := make(map[string]bool, len(targetGroups))
+		for _, g := range targetGroups {
+			have[g.GroupID] = true
+		}
+		groups := make([]awsvc.UserMembership, 0, len(refGroups))
+		existing := 0
+		for _, g := range refGroups {
+			if have[g.GroupID] {
+				existing++
+				continue
+			}
+			groups = append(groups, g)
+		}
+
+		return mirrorPlanMsg{ref: ref, target: target, groups: groups, existing: existing}
+	}
+}
+
+func mirrorAccessCmd(svc *awsvc.Service, ref, target awsvc.User, groups []awsvc.UserMembership) tea.Cmd {
+	return func() tea.Msg {
+		report := svc.AddUserToGroups(context.Background(), target, groups)
+		return bulkMsg{operation: fmt.Sprintf("Mirror access of %s to %s", ref.DisplayName, target.DisplayName), report: report}
+	}
+}
+
+func executeOffboardCmd(svc *awsvc.Service, plan awsvc.OffboardPlan) tea.Cmd {
+	return func() tea.Msg {
+		changes := svc.ExecuteOffboard(context.Background(), plan)
+		operation := "Offboard " + plan.User.DisplayName
+
+		report := offboard.NewReport(svc, plan, changes)
+		keyPath, err := signing.DefaultKeyPath()
+		if err == nil {
+			err = report.Sign(keyPath)
+		}
+		var path string
+		if err == nil {
+			path, err = offboard.DefaultReportPath(plan.User, report.GeneratedAt)
+		}
+		if err == nil {
+			err = report.Write(path)
+		}
+		if err != nil {
+			return bulkMsg{operation: operation, report: changes, err: fmt.Errorf("write signed report: %w", err)}
+		}
+
+		return bulkMsg{operation: operation + " (signed report: " + path + ")", report: changes}
+	}
+}
+
+func mergeGroupsCmd(svc *awsvc.Service, sources []awsvc.Group, target awsvc.Group) tea.Cmd {
+	return func() tea.Msg {
+		report, err := svc.MergeGroups(context.Background(), sources, target)
+		return mergeMsg{operation: "Merge into " + target.DisplayName, report: report, err: err}
 	}
 }
 
-func addUserCmd(svc *awsvc.Service, groupID, userID string) tea.Cmd {
+func deleteMergedSourcesCmd(svc *awsvc.Service, sources []awsvc.Group, mergeReport awsvc.ChangeReport) tea.Cmd {
 	return func() tea.Msg {
-		err := svc.AddUserToGroup(context.Background(), groupID, userID)
-		return mutationMsg{operation: "Add user", err: err}
+		deleted := svc.DeleteGroups(context.Background(), sources)
+		report := awsvc.ChangeReport{Results: append(append([]awsvc.ChangeResult{}, mergeReport.Results...), deleted.Results...)}
+		return bulkMsg{operation: "Merge and delete sources", report: report}
 	}
 }
 
-func removeUserCmd(svc *awsvc.Service, membershipID string) tea.Cmd {
+func compareGroupsCmd(svc *awsvc.Service, a, b awsvc.Group, sets []awsvc.PermissionSet) tea.Cmd {
 	return func() tea.Msg {
-		err := svc.RemoveUserFromGroup(context.Background(), membershipID)
-		return mutationMsg{operation: "Remove user", err: err}
+		ctx := context.Background()
+		if len(sets) == 0 {
+			loaded, err := svc.ListPermissionSets(ctx)
+			if err == nil {
+				sets = loaded
+			}
+		}
+		comparison, err := svc.CompareGroups(ctx, a, b, sets)
+		return compareMsg{comparison: comparison, permissionSets: sets, err: err}
+	}
+}
+
+func syncGroupsCmd(svc *awsvc.Service, comparison awsvc.GroupComparison, fromA, prune bool) tea.Cmd {
+	return func() tea.Msg {
+		from, to := comparison.A, comparison.B
+		if !fromA {
+			from, to = to, from
+		}
+		report := svc.SyncGroups(context.Background(), comparison, fromA, prune)
+		return bulkMsg{operation: fmt.Sprintf("Sync %s → %s", from.DisplayName, to.DisplayName), report: report}
 	}
 }
 
@@ -1152,17 +3047,42 @@ func discoverAccountsAssignmentsCmd(ctx context.Context, svc *awsvc.Service, gro
 	}
 }
 
-func createAssignmentCmd(svc *awsvc.Service, groupID, accountID, permissionSetARN string) tea.Cmd {
+func createAssignmentCmd(svc *awsvc.Service, group awsvc.Group, a awsvc.Assignment) tea.Cmd {
+	return func() tea.Msg {
+		err := svc.CreateAssignment(context.Background(), group.ID, a.AccountID, a.PermissionSetARN)
+		return mutationMsg{
+			operation: "Create assignment",
+			err:       err,
+			inverse: &inverseOp{
+				label: fmt.Sprintf("Delete %s on %s from %s", cmp.Or(a.PermissionSetName, shortARN(a.PermissionSetARN)), a.AccountID, group.DisplayName),
+				cmd:   deleteAssignmentCmd(svc, group, a),
+			},
+		}
+	}
+}
+
+func deleteAssignmentCmd(svc *awsvc.Service, group awsvc.Group, a awsvc.Assignment) tea.Cmd {
 	return func() tea.Msg {
-		err := svc.CreateAssignment(context.Background(), groupID, accountID, permissionSetARN)
-		return mutationMsg{operation: "Create assignment", err: err}
+		err := svc.DeleteAssignment(context.Background(), group.ID, a.AccountID, a.PermissionSetARN)
+		return mutationMsg{
+			operation: "Delete assignment",
+			err:       err,
+			inverse: &inverseOp{
+				label: fmt.Sprintf("Recreate %s on %s for %s", cmp.Or(a.PermissionSetName, shortARN(a.PermissionSetARN)), a.AccountID, group.DisplayName),
+				cmd:   createAssignmentCmd(svc, group, a),
+			},
+		}
 	}
 }
 
-func deleteAssignmentCmd(svc *awsvc.Service, groupID, accountID, permissionSetARN string) tea.Cmd {
+func loadAuditCmd(auditLog *audit.Log) tea.Cmd {
 	return func() tea.Msg {
-		err := svc.DeleteAssignment(context.Background(), groupID, accountID, permissionSetARN)
-		return mutationMsg{operation: "Delete assignment", err: err}
+		records, err := auditLog.ReadAll()
+		if err != nil {
+			return auditMsg{err: err}
+		}
+		_, problems, err := auditLog.Verify()
+		return auditMsg{records: records, problems: len(problems), err: err}
 	}
 }
 
@@ -1176,25 +3096,32 @@ func newList(styles theme.Styles) list.Model {
 	return l
 }
 
-func regionsToItems() []list.Item {
-	items := make([]list.Item, 0, len(awsRegions))
-	for _, region := range awsRegions {
+func regionsToItems(regions []string) []list.Item {
+	items := make([]list.Item, 0, len(regions)+1)
+	items = append(items, uiItem{id: awsvc.AllRegions, title: "All regions", desc: "Search every enabled region for Identity Center instances"})
+	for _, region := range regions {
 		items = append(items, uiItem{id: region, title: region, desc: "AWS region"})
 	}
 	return items
 }
 
-func profilesToItems(profiles []string) []list.Item {
-	items := make([]list.Item, 0, len(profiles))
-	for _, profile := range profiles {
-		items = append(items, uiItem{id: profile, title: profile, desc: "AWS profile"})
+func profilesToItems(all []profiles.Profile) []list.Item {
+	items := make([]list.Item, 0, len(all)+1)
+	items = append(items, uiItem{
+		id:    profiles.NoProfile,
+		title: profiles.NoProfile,
+		desc:  "Default credential chain: environment variables, container or instance role",
+		raw:   profiles.Profile{Name: profiles.NoProfile, Region: profiles.EnvRegion()},
+	})
+	for _, p := range all {
+		items = append(items, uiItem{id: p.Name, title: p.Name, desc: p.Description(), raw: p})
 	}
 	return items
 }
 
 const unknownCount = -1
 
-func groupsToItems(groups []awsvc.Group, selectedID string, selectedCount int) []list.Item {
+func groupsToItems(groups []awsvc.Group, selectedID string, selectedCount int, marked map[string]bool) []list.Item {
 	items := make([]list.Item, 0, len(groups))
 	for _, g := range groups {
 		desc := "Users: -"
@@ -1205,7 +3132,7 @@ func groupsToItems(groups []awsvc.Group, selectedID string, selectedCount int) [
 				desc = "Users: " + strconv.Itoa(selectedCount)
 			}
 		}
-		items = append(items, uiItem{id: g.ID, title: g.DisplayName, desc: desc, raw: g})
+		items = append(items, uiItem{id: g.ID, title: g.DisplayName, desc: desc, raw: g, marked: marked[g.ID]})
 	}
 	return items
 }
@@ -1217,19 +3144,31 @@ func instancesToItems(instances []awsvc.Instance) []list.Item {
 		if name == "" {
 			name = shortARN(instance.ARN)
 		}
-		items = append(items, uiItem{id: instance.ARN, title: name, desc: instance.IdentityStore, raw: instance})
+		desc := instance.IdentityStore
+		if instance.Region != "" {
+			desc += " | " + instance.Region
+		}
+		items = append(items, uiItem{id: instance.ARN, title: name, desc: desc, raw: instance})
 	}
 	return items
 }
 
-func groupUsersToItems(users []awsvc.GroupUser) []list.Item {
+func groupUsersToItems(users []awsvc.GroupUser, marked map[string]bool) []list.Item {
 	items := make([]list.Item, 0, len(users))
 	for _, user := range users {
 		desc := user.Email
 		if desc == "" {
 			desc = user.UserID
 		}
-		items = append(items, uiItem{id: user.MembershipID, title: user.DisplayName, desc: desc, raw: user})
+		items = append(items, uiItem{id: user.MembershipID, title: user.DisplayName, desc: desc, raw: user, marked: marked[user.MembershipID]})
+	}
+	return items
+}
+
+func searchResultsToItems(results []awsvc.SearchResult) []list.Item {
+	items := make([]list.Item, 0, len(results))
+	for _, result := range results {
+		items = append(items, uiItem{id: result.Kind + ":" + result.Detail(), title: result.Kind + ": " + result.Title(), desc: result.Detail(), raw: result})
 	}
 	return items
 }
@@ -1265,12 +3204,112 @@ func permissionSetsToItems(sets []awsvc.PermissionSet) []list.Item {
 func assignmentsToItems(assignments []awsvc.Assignment) []list.Item {
 	items := make([]list.Item, 0, len(assignments))
 	for _, a := range assignments {
-		title := fmt.Sprintf("%s (%s)", fallback(a.AccountName, a.AccountID), a.AccountID)
+		title := fmt.Sprintf("%s (%s)", cmp.Or(a.AccountName, a.AccountID), a.AccountID)
 		items = append(items, uiItem{id: a.AccountID + "|" + a.PermissionSetARN, title: title, desc: a.PermissionSetName, raw: a})
 	}
 	return items
 }
 
+func auditRecordsToItems(records []audit.Record) []list.Item {
+	items := make([]list.Item, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		title := fmt.Sprintf("%s  %s  %s", rec.Time.Local().Format("2006-01-02 15:04:05"), rec.Action, rec.Outcome)
+		desc := auditTargetsText(rec.Targets) + fmt.Sprintf(" | %s@%s/%s", rec.OSUser, rec.Profile, rec.Region)
+		if rec.RequestID != "" {
+			desc += " | req " + rec.RequestID
+		}
+		if rec.Error != "" {
+			desc += " | " + rec.Error
+		}
+		items = append(items, uiItem{id: strconv.Itoa(i), title: title, desc: desc, raw: rec})
+	}
+	return items
+}
+
+func auditTargetsText(t audit.Targets) string {
+	parts := make([]string, 0, 6)
+	if t.GroupName != "" {
+		parts = append(parts, "group "+t.GroupName)
+	}
+	if t.GroupID != "" {
+		parts = append(parts, "group_id "+t.GroupID)
+	}
+	if t.UserID != "" {
+		parts = append(parts, "user "+t.UserID)
+	}
+	if t.MembershipID != "" {
+		parts = append(parts, "membership "+t.MembershipID)
+	}
+	if t.AccountID != "" {
+		parts = append(parts, "account "+t.AccountID)
+	}
+	if t.PermissionSetARN != "" {
+		parts = append(parts, "permission_set "+shortARN(t.PermissionSetARN))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func mirrorGroupsToItems(groups []awsvc.UserMembership, selected map[string]bool) []list.Item {
+	items := make([]list.Item, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, uiItem{id: g.GroupID, title: g.GroupName, desc: g.GroupID, raw: g, marked: selected[g.GroupID]})
+	}
+	return items
+}
+
+func comparisonToItems(comparison awsvc.GroupComparison, accounts []awsvc.Account) []list.Item {
+	accountNames := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Name
+	}
+
+	items := make([]list.Item, 0)
+	addUsers := func(side string, users []awsvc.GroupUser) {
+		for _, user := range users {
+			desc := cmp.Or(user.Email, user.UserID)
+			items = append(items, uiItem{id: side + "|" + user.UserID, title: side + "  " + user.DisplayName, desc: "member | " + desc, raw: user})
+		}
+	}
+	addAssignments := func(side string, assignments []awsvc.Assignment) {
+		for _, a := range assignments {
+			account := a.AccountID
+			if name := accountNames[a.AccountID]; name != "" {
+				account = fmt.Sprintf("%s (%s)", name, a.AccountID)
+			}
+			title := fmt.Sprintf("%s  %s on %s", side, cmp.Or(a.PermissionSetName, shortARN(a.PermissionSetARN)), account)
+			items = append(items, uiItem{id: side + "|" + a.AccountID + "|" + a.PermissionSetARN, title: title, desc: "assignment | " + a.PermissionSetARN, raw: a})
+		}
+	}
+
+	addUsers("A only", comparison.OnlyAUsers)
+	addUsers("B only", comparison.OnlyBUsers)
+	addUsers("Both  ", comparison.BothUsers)
+	addAssignments("A only", comparison.OnlyAAssignments)
+	addAssignments("B only", comparison.OnlyBAssignments)
+	addAssignments("Both  ", comparison.BothAssignments)
+	return items
+}
+
+func pastedQueries(text string) []string {
+	seen := make(map[string]bool)
+	queries := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' || r == '\t' }) {
+			query := strings.TrimSpace(field)
+			if query == "" || strings.HasPrefix(query, "#") || seen[strings.ToLower(query)] {
+				continue
+			}
+			seen[strings.ToLower(query)] = true
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
 func selectedItem(l list.Model) uiItem {
 	items := l.Items()
 	idx := l.Index()
@@ -1284,18 +3323,16 @@ func selectedItem(l list.Model) uiItem {
 	return item
 }
 
+func highlighted(l list.Model) uiItem {
+	item, _ := l.SelectedItem().(uiItem)
+	return item
+}
+
 func shortARN(arn string) string {
 	parts := strings.Split(arn, "/")
 	return parts[len(parts)-1]
 }
 
-func fallback(value, fallbackValue string) string {
-	if strings.TrimSpace(value) == "" {
-		return fallbackValue
-	}
-	return value
-}
-
 func isAccountID(value string) bool {
 	if len(value) != 12 {
 		return false
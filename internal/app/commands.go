@@ -0,0 +1,410 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/config"
+	"aws-groups-manager/internal/profiles"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxCommandSuggestions = 8
+
+type command struct {
+	name     string
+	aliases  []string
+	usage    string
+	summary  string
+	instance bool
+	args     func(m *model) []string
+	run      func(m *model, arg string) tea.Cmd
+}
+
+func commandTable() []command {
+	return []command{
+		{name: "groups", aliases: []string{"g"}, summary: "Groups screen", instance: true, run: (*model).runGroupsCommand},
+		{name: "group", usage: "<name|id>", summary: "Open a group", instance: true, args: groupNameArgs, run: (*model).runGroupCommand},
+		{name: "user", usage: "<user name|email|id>", summary: "Show a user's groups and direct assignments", instance: true, run: (*model).runUserCommand},
+		{name: "account", usage: "<id|name>", summary: "Show who has access to an account", instance: true, args: accountArgs, run: (*model).runAccountCommand},
+		{name: "permission-set", aliases: []string{"ps"}, usage: "<name|arn>", summary: "Show where a permission set is assigned", instance: true, args: permissionSetArgs, run: (*model).runPermissionSetCommand},
+		{name: "find", usage: "[query]", summary: "Open the find palette", instance: true, run: (*model).runFindCommand},
+		{name: "profile", usage: "<name>", summary: "Switch AWS profile", args: profileArgs, run: (*model).runProfileCommand},
+		{name: "region", usage: "<region>", summary: "Switch AWS region", args: regionArgs, run: (*model).runRegionCommand},
+		{name: "context", aliases: []string{"ctx"}, usage: "<name>", summary: "Switch to a saved context (profile, region, instance)", args: contextArgs, run: (*model).runContextCommand},
+		{name: "instance", summary: "Pick another Identity Center instance", run: (*model).runInstanceCommand},
+		{name: "audit", summary: "Open the audit log", run: func(m *model, _ string) tea.Cmd { return m.openAuditLog() }},
+		{name: "refresh", summary: "Reload the current screen", run: func(m *model, _ string) tea.Cmd { return m.refreshCurrentScreen() }},
+		{name: "undo", summary: "Undo the last user or assignment change", run: func(m *model, _ string) tea.Cmd { return m.requestUndo() }},
+		{name: "help", summary: "Show help", run: (*model).runHelpCommand},
+		{name: "quit", aliases: []string{"q"}, summary: "Quit", run: (*model).runQuitCommand},
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	name = strings.ToLower(name)
+	for _, c := range commandTable() {
+		if c.name == name {
+			return c, true
+		}
+		for _, alias := range c.aliases {
+			if alias == name {
+				return c, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+func splitCommandLine(line string) (string, string, bool) {
+	line = strings.TrimPrefix(strings.TrimLeft(line, " "), ":")
+	name, arg, hasArg := strings.Cut(line, " ")
+	return name, strings.TrimSpace(arg), hasArg
+}
+
+func (m *model) openCommandLine() {
+	m.modal = modalCommand
+	m.input.SetValue("")
+	m.input.Placeholder = "command, e.g. group admins, user alice, profile prod"
+	m.input.Focus()
+	m.commandProfiles = profileNames()
+	m.commandContexts = m.contexts()
+	m.updateCommandSuggestions()
+}
+
+func (m *model) runCommandLine(line string) tea.Cmd {
+	name, arg, _ := splitCommandLine(line)
+	if name == "" {
+		return nil
+	}
+
+	c, ok := lookupCommand(name)
+	if !ok {
+		m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Unknown command %q (Tab completes, :help lists keys)", name)}
+		return nil
+	}
+	if c.usage != "" && !strings.HasPrefix(c.usage, "[") && arg == "" {
+		m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Usage: :%s %s", c.name, c.usage)}
+		return nil
+	}
+	if c.instance && (m.svc == nil || m.instance.ARN == "") {
+		m.status = statusMessage{level: statusWarn, text: "Select an Identity Center instance first"}
+		return nil
+	}
+	if m.busy && c.name != "quit" && c.name != "help" {
+		m.status = statusMessage{level: statusWarn, text: "Still loading, try again in a moment"}
+		return nil
+	}
+
+	return c.run(m, arg)
+}
+
+func (m *model) commandCandidates() (string, []string) {
+	name, arg, hasArg := splitCommandLine(m.input.Value())
+	if !hasArg {
+		names := make([]string, 0, 16)
+		for _, c := range commandTable() {
+			for _, n := range append([]string{c.name}, c.aliases...) {
+				if strings.HasPrefix(n, strings.ToLower(name)) {
+					names = append(names, n)
+				}
+			}
+		}
+		return "", names
+	}
+
+	c, ok := lookupCommand(name)
+	if !ok || c.args == nil {
+		return name + " ", nil
+	}
+	values := make([]string, 0, 16)
+	needle := strings.ToLower(arg)
+	for _, v := range c.args(m) {
+		if strings.HasPrefix(strings.ToLower(v), needle) {
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return name + " ", values
+}
+
+func (m *model) completeCommandLine() {
+	prefix, candidates := m.commandCandidates()
+	if len(candidates) == 0 {
+		return
+	}
+
+	completed := candidates[0]
+	if len(candidates) > 1 {
+		completed = commonPrefix(candidates)
+	} else if prefix == "" {
+		completed += " "
+	}
+	name, arg, _ := splitCommandLine(m.input.Value())
+	typed := arg
+	if prefix == "" {
+		typed = name
+	}
+	if len(strings.TrimSpace(completed)) < len(typed) {
+		return
+	}
+
+	m.input.SetValue(prefix + completed)
+	m.input.CursorEnd()
+	m.updateCommandSuggestions()
+}
+
+func (m *model) updateCommandSuggestions() {
+	_, candidates := m.commandCandidates()
+	m.commandSuggestions = m.commandSuggestions[:0]
+	name, _, hasArg := splitCommandLine(m.input.Value())
+
+	if !hasArg {
+		seen := make(map[string]bool)
+		for _, n := range candidates {
+			c, _ := lookupCommand(n)
+			if seen[c.name] {
+				continue
+			}
+			seen[c.name] = true
+			m.commandSuggestions = append(m.commandSuggestions, fmt.Sprintf(":%-16s %s", strings.TrimSpace(c.name+" "+c.usage), c.summary))
+		}
+	} else if c, ok := lookupCommand(name); ok {
+		m.commandSuggestions = append(m.commandSuggestions, fmt.Sprintf(":%s %s  %s", c.name, c.usage, c.summary))
+		for _, v := range candidates {
+			m.commandSuggestions = append(m.commandSuggestions, "  "+v)
+		}
+	}
+
+	if len(m.commandSuggestions) > maxCommandSuggestions {
+		more := len(m.commandSuggestions) - maxCommandSuggestions
+		m.commandSuggestions = append(m.commandSuggestions[:maxCommandSuggestions], fmt.Sprintf("  ... %d more", more))
+	}
+}
+
+func (m *model) runGroupsCommand(_ string) tea.Cmd {
+	if len(m.groups) == 0 {
+		m.screen = screenGroups
+		m.configureListForGroups()
+		m.busy = true
+		return loadGroupsCmd(m.svc)
+	}
+	return m.restoreScreen(screenGroups)
+}
+
+func (m *model) runGroupCommand(arg string) tea.Cmd {
+	for _, g := range m.knownGroups() {
+		if g.ID == arg || strings.EqualFold(g.DisplayName, arg) {
+			return m.openGroupDetail(g)
+		}
+	}
+	m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Group %q not found", arg)}
+	return nil
+}
+
+func (m *model) runUserCommand(arg string) tea.Cmd {
+	return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindUser, User: awsvc.User{ID: arg, DisplayName: arg}})
+}
+
+func (m *model) runAccountCommand(arg string) tea.Cmd {
+	names := m.entityNames()
+	for id, name := range names.accounts {
+		if id == arg || strings.EqualFold(name, arg) {
+			return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindAccount, Account: awsvc.Account{ID: id, Name: name}})
+		}
+	}
+	if isAccountID(arg) {
+		return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindAccount, Account: awsvc.Account{ID: arg}})
+	}
+	m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Account %q not found", arg)}
+	return nil
+}
+
+func (m *model) runPermissionSetCommand(arg string) tea.Cmd {
+	names := m.entityNames()
+	for arn, name := range names.permissionSets {
+		if arn == arg || strings.EqualFold(name, arg) {
+			return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindPermissionSet, PermissionSet: awsvc.PermissionSet{ARN: arn, Name: name}})
+		}
+	}
+	if strings.HasPrefix(arg, "arn:") {
+		return m.openSearchResult(awsvc.SearchResult{Kind: awsvc.KindPermissionSet, PermissionSet: awsvc.PermissionSet{ARN: arg, Name: shortARN(arg)}})
+	}
+	m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Permission set %q not found (open a group's Accounts tab or :find to load permission sets)", arg)}
+	return nil
+}
+
+func (m *model) runFindCommand(arg string) tea.Cmd {
+	cmd := m.openPalette()
+	if arg == "" {
+		return cmd
+	}
+	m.input.SetValue(arg)
+	m.input.CursorEnd()
+	m.searchSeq++
+	return tea.Batch(cmd, m.searchPalette(m.searchSeq))
+}
+
+func (m *model) runContextCommand(arg string) tea.Cmd {
+	ctx, ok := m.commandContexts[arg]
+	if !ok {
+		m.status = statusMessage{level: statusWarn, text: fmt.Sprintf("Context %q not found (create one with `aws-groups-manager context add`)", arg)}
+		return nil
+	}
+	m.context = arg
+	m.profile = ctx.Profile
+	m.region = ctx.Region
+	m.regionFromProfile = false
+	m.wantInstance = ctx.InstanceARN
+	m.wantStore = ""
+	return m.switchSession()
+}
+
+func (m *model) runProfileCommand(arg string) tea.Cmd {
+	m.context = ""
+	m.wantInstance = ""
+	m.wantStore = ""
+	if m.region == "" || m.regionFromProfile {
+		m.region = profiles.Region(arg)
+		m.regionFromProfile = m.region != ""
+	}
+	m.profile = arg
+	if m.region == "" {
+		return m.restoreScreen(screenRegion)
+	}
+	return m.switchSession()
+}
+
+func (m *model) runRegionCommand(arg string) tea.Cmd {
+	m.context = ""
+	m.wantInstance = ""
+	m.wantStore = ""
+	m.region = arg
+	m.regionFromProfile = false
+	if m.profile == "" {
+		return m.restoreScreen(screenProfile)
+	}
+	return m.switchSession()
+}
+
+func (m *model) runInstanceCommand(_ string) tea.Cmd {
+	if len(m.instances) < 2 {
+		m.status = statusMessage{level: statusWarn, text: "Only one Identity Center instance is available"}
+		return nil
+	}
+	return m.restoreScreen(screenInstance)
+}
+
+func (m *model) runHelpCommand(_ string) tea.Cmd {
+	m.modal = modalHelp
+	return nil
+}
+
+func (m *model) runQuitCommand(_ string) tea.Cmd {
+	if m.discoverCancel != nil {
+		m.discoverCancel()
+	}
+	m.cancelSearch()
+	return tea.Quit
+}
+
+func (m *model) switchSession() tea.Cmd {
+	m.instance = awsvc.Instance{}
+	m.groups = nil
+	m.searchIndex = nil
+	m.lastInverse = nil
+	m.screen = screenEnsureSession
+	m.setListItems(nil)
+	m.busy = true
+	m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Checking AWS session for %s in %s", m.profile, m.region)}
+	return m.ensureSessionCmd()
+}
+
+func (m *model) knownGroups() []awsvc.Group {
+	if len(m.groups) == 0 && m.searchIndex != nil {
+		return m.searchIndex.Groups
+	}
+	return m.groups
+}
+
+func groupNameArgs(m *model) []string {
+	groups := m.knownGroups()
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.DisplayName)
+	}
+	return names
+}
+
+func accountArgs(m *model) []string {
+	names := m.entityNames()
+	values := make([]string, 0, len(names.accounts))
+	for id := range names.accounts {
+		values = append(values, id)
+	}
+	return values
+}
+
+func permissionSetArgs(m *model) []string {
+	names := m.entityNames()
+	values := make([]string, 0, len(names.permissionSets))
+	for _, name := range names.permissionSets {
+		values = append(values, name)
+	}
+	return values
+}
+
+func contextArgs(m *model) []string {
+	names := make([]string, 0, len(m.commandContexts))
+	for name := range m.commandContexts {
+		names = append(names, name)
+	}
+	return names
+}
+
+func regionArgs(m *model) []string {
+	return append([]string{awsvc.AllRegions}, m.regions...)
+}
+
+func profileArgs(m *model) []string {
+	return m.commandProfiles
+}
+
+func profileNames() []string {
+	list, err := loadProfiles()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(list)+1)
+	names = append(names, profiles.NoProfile)
+	for _, p := range list {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func (m *model) contexts() map[string]config.Context {
+	path, err := config.DefaultContextsPath()
+	if err != nil {
+		return m.startCfg.Contexts
+	}
+	contexts, err := config.LoadContexts(path)
+	if err != nil {
+		m.setStatusErr("Could not reload contexts", err)
+		return m.startCfg.Contexts
+	}
+	return contexts.Contexts
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(strings.ToLower(v), strings.ToLower(prefix)) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
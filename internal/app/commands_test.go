@@ -0,0 +1,38 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"aws-groups-manager/internal/config"
+)
+
+func TestCommandContextsReloaded(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path, err := config.DefaultContextsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	body := "contexts:\n  prod:\n    profile: org-prod\n    region: eu-west-1\n"
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &model{startCfg: StartConfig{Contexts: map[string]config.Context{"removed": {Profile: "old"}}}}
+	m.commandContexts = m.contexts()
+
+	names := contextArgs(m)
+	sort.Strings(names)
+	if len(names) != 1 || names[0] != "prod" {
+		t.Fatalf("contextArgs = %v, want [prod] from contexts.yaml", names)
+	}
+	if got := m.commandContexts["prod"]; got.Profile != "org-prod" || got.Region != "eu-west-1" {
+		t.Errorf("prod context = %+v", got)
+	}
+}
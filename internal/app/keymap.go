@@ -0,0 +1,348 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type action string
+
+const (
+	actQuit         action = "quit"
+	actHelp         action = "help"
+	actErrorDetails action = "error_details"
+	actFilter       action = "filter"
+	actRefresh      action = "refresh"
+	actUndo         action = "undo"
+	actAuditLog     action = "audit_log"
+	actFind         action = "find"
+	actCommand      action = "command"
+	actMark         action = "mark"
+	actCreateGroup  action = "create_group"
+	actCloneGroup   action = "clone_group"
+	actCompare      action = "compare"
+	actMerge        action = "merge"
+	actMirrorAccess action = "mirror_access"
+	actOffboard     action = "offboard"
+	actDeleteGroup  action = "delete_group"
+	actSync         action = "sync"
+	actAdd          action = "add"
+	actPasteUsers   action = "paste_users"
+	actRemove       action = "remove"
+	actCopyMove     action = "copy_move"
+	actSubmit       action = "submit"
+	actPickerPaste  action = "picker_paste"
+	actConfirm      action = "confirm"
+	actCancel       action = "cancel"
+	actToggle       action = "toggle"
+	actNextField    action = "next_field"
+)
+
+var dialogActions = map[action]bool{
+	actSubmit:      true,
+	actPickerPaste: true,
+	actConfirm:     true,
+	actCancel:      true,
+	actToggle:      true,
+	actNextField:   true,
+}
+
+const (
+	presetCtrl = "ctrl"
+	presetVim  = "vim"
+)
+
+var keyPresets = map[string]map[action][]string{
+	presetCtrl: {
+		actQuit:         {"ctrl+c"},
+		actHelp:         {"ctrl+g"},
+		actErrorDetails: {"ctrl+e"},
+		actFilter:       {"ctrl+f"},
+		actRefresh:      {"ctrl+r"},
+		actUndo:         {"ctrl+z"},
+		actAuditLog:     {"ctrl+l"},
+		actFind:         {"ctrl+w"},
+		actCommand:      {":"},
+		actMark:         {" "},
+		actCreateGroup:  {"ctrl+n"},
+		actCloneGroup:   {"ctrl+y"},
+		actCompare:      {"ctrl+o"},
+		actMerge:        {"ctrl+u"},
+		actMirrorAccess: {"ctrl+k"},
+		actOffboard:     {"ctrl+b"},
+		actDeleteGroup:  {"ctrl+d"},
+		actSync:         {"ctrl+s"},
+		actAdd:          {"ctrl+a"},
+		actPasteUsers:   {"ctrl+p"},
+		actRemove:       {"ctrl+x"},
+		actCopyMove:     {"ctrl+t"},
+		actSubmit:       {"ctrl+s"},
+		actPickerPaste:  {"ctrl+p"},
+		actConfirm:      {"enter"},
+		actCancel:       {"esc"},
+		actToggle:       {" "},
+		actNextField:    {"tab", "shift+tab"},
+	},
+	presetVim: {
+		actQuit:         {"ctrl+c", "q"},
+		actHelp:         {"?"},
+		actErrorDetails: {"E"},
+		actFilter:       {"ctrl+f"},
+		actRefresh:      {"r"},
+		actUndo:         {"u"},
+		actAuditLog:     {"L"},
+		actFind:         {"f"},
+		actCommand:      {":"},
+		actMark:         {" ", "v"},
+		actCreateGroup:  {"n"},
+		actCloneGroup:   {"y"},
+		actCompare:      {"o"},
+		actMerge:        {"m"},
+		actMirrorAccess: {"M"},
+		actOffboard:     {"B"},
+		actDeleteGroup:  {"D"},
+		actSync:         {"S"},
+		actAdd:          {"a"},
+		actPasteUsers:   {"p"},
+		actRemove:       {"x"},
+		actCopyMove:     {"t"},
+		actSubmit:       {"ctrl+s"},
+		actPickerPaste:  {"ctrl+p"},
+		actConfirm:      {"enter"},
+		actCancel:       {"esc"},
+		actToggle:       {" "},
+		actNextField:    {"tab", "shift+tab"},
+	},
+}
+
+type keyMap struct {
+	preset string
+	keys   map[action][]string
+}
+
+type hint struct {
+	act  action
+	text string
+}
+
+func defaultKeyMap() keyMap {
+	km, _ := presetKeyMap(presetCtrl)
+	return km
+}
+
+func presetKeyMap(name string) (keyMap, error) {
+	preset, ok := keyPresets[name]
+	if !ok {
+		names := make([]string, 0, len(keyPresets))
+		for n := range keyPresets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return keyMap{}, fmt.Errorf("unknown key preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	km := keyMap{preset: name, keys: make(map[action][]string, len(preset))}
+	for act, keys := range preset {
+		km.keys[act] = append([]string(nil), keys...)
+	}
+	return km, nil
+}
+
+func loadKeyMap(preset string, bindings map[string][]string) (keyMap, error) {
+	if preset == "" {
+		preset = presetCtrl
+	}
+	km, err := presetKeyMap(preset)
+	if err != nil {
+		return keyMap{}, err
+	}
+
+	for name, keys := range bindings {
+		act := action(name)
+		if _, ok := km.keys[act]; !ok {
+			return keyMap{}, fmt.Errorf("key_bindings: unknown action %q", name)
+		}
+		if len(keys) == 0 {
+			return keyMap{}, fmt.Errorf("key_bindings: action %q has no keys", name)
+		}
+		km.keys[act] = keys
+	}
+
+	if err := km.checkDuplicates(); err != nil {
+		return keyMap{}, err
+	}
+	return km, nil
+}
+
+func (k keyMap) checkDuplicates() error {
+	acts := make([]action, 0, len(k.keys))
+	for act := range k.keys {
+		acts = append(acts, act)
+	}
+	sort.Slice(acts, func(i, j int) bool { return acts[i] < acts[j] })
+
+	owners := make(map[string]action, len(acts)*2)
+	for _, act := range acts {
+		for _, key := range k.keys[act] {
+			scoped := key
+			if dialogActions[act] {
+				scoped = "dialog:" + key
+			}
+			if other, ok := owners[scoped]; ok && other != act {
+				return fmt.Errorf("key %s is bound to both %s and %s", keyLabel(key), other, act)
+			}
+			owners[scoped] = act
+		}
+	}
+	return nil
+}
+
+func (k keyMap) is(act action, key string) bool {
+	for _, candidate := range k.keys[act] {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (k keyMap) bound(key string) bool {
+	for act, keys := range k.keys {
+		if dialogActions[act] {
+			continue
+		}
+		for _, candidate := range keys {
+			if candidate == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (k keyMap) label(act action) string {
+	keys := k.keys[act]
+	if len(keys) == 0 {
+		return ""
+	}
+	return keyLabel(keys[0])
+}
+
+func (k keyMap) labels(act action) string {
+	labels := make([]string, 0, len(k.keys[act]))
+	for _, key := range k.keys[act] {
+		labels = append(labels, keyLabel(key))
+	}
+	return strings.Join(labels, "/")
+}
+
+func (k keyMap) render(hints []hint) []string {
+	items := make([]string, 0, len(hints))
+	for _, h := range hints {
+		if label := k.label(h.act); label != "" {
+			items = append(items, label+" "+h.text)
+		}
+	}
+	return items
+}
+
+func (k keyMap) dialogHint(text string) string {
+	return strings.NewReplacer(
+		"Enter", k.label(actConfirm),
+		"Esc", k.label(actCancel),
+		"Space", k.label(actToggle),
+		"Tab", k.label(actNextField),
+	).Replace(text)
+}
+
+func (m model) renderHint(text string) string {
+	return m.styles.ModalHint.Render(m.keys.dialogHint(text))
+}
+
+func keyLabel(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case "enter":
+		return "Enter"
+	case "esc":
+		return "Esc"
+	case "tab":
+		return "Tab"
+	case "shift+tab":
+		return "Shift+Tab"
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "^" + strings.ToUpper(rest)
+	}
+	return key
+}
+
+func (m model) typingInFilter(msg tea.KeyMsg) bool {
+	return m.list.FilterState() == list.Filtering && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace)
+}
+
+var helpSections = []struct {
+	title string
+	hints []hint
+}{
+	{"Global", []hint{
+		{actCommand, "command mode (:groups, :user <name>, :account <id>, :profile <name>, ...)"},
+		{actFind, "find a group, user, account or permission set"},
+		{actFilter, "toggle list filter"},
+		{actRefresh, "refresh the current screen"},
+		{actAuditLog, "audit log"},
+		{actUndo, "undo the last user or assignment change"},
+		{actErrorDetails, "last error details"},
+		{actHelp, "this help"},
+		{actQuit, "quit"},
+	}},
+	{"Groups", []hint{
+		{actMark, "mark group (for merge)"},
+		{actCreateGroup, "create group"},
+		{actCloneGroup, "clone group"},
+		{actCompare, "compare with another group"},
+		{actMerge, "merge marked groups into a target"},
+		{actMirrorAccess, "mirror a reference user's groups to a new user"},
+		{actOffboard, "offboard a user"},
+		{actDeleteGroup, "delete group"},
+	}},
+	{"Group users", []hint{
+		{actMark, "mark user (for copy/move)"},
+		{actAdd, "add user"},
+		{actPasteUsers, "add users from a pasted list"},
+		{actCopyMove, "copy or move marked users"},
+		{actOffboard, "offboard the highlighted user"},
+		{actRemove, "remove user"},
+	}},
+	{"Group accounts", []hint{
+		{actAdd, "add assignment"},
+		{actRemove, "remove assignment"},
+	}},
+	{"Compare", []hint{
+		{actSync, "sync one group to the other"},
+	}},
+	{"Dialogs", []hint{
+		{actConfirm, "confirm or pick the highlighted item"},
+		{actCancel, "close the dialog"},
+		{actToggle, "toggle the focused option or mark a group"},
+		{actNextField, "next field, or complete a command"},
+		{actPickerPaste, "switch the add-user picker to a pasted list"},
+		{actSubmit, "resolve a pasted list"},
+	}},
+}
+
+func (k keyMap) helpText() string {
+	lines := []string{fmt.Sprintf("Key preset: %s | Navigation: arrows, PgUp/PgDn, Enter, Esc", k.preset)}
+	for _, section := range helpSections {
+		lines = append(lines, "", section.title)
+		for _, h := range section.hints {
+			lines = append(lines, fmt.Sprintf("  %-10s %-14s %s", k.labels(h.act), h.act, h.text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadKeyMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   string
+		bindings map[string][]string
+		wantErr  string
+	}{
+		{name: "default preset"},
+		{name: "vim preset", preset: presetVim},
+		{name: "override", bindings: map[string][]string{"add": {"ctrl+a", "insert"}, "confirm": {"enter", "ctrl+j"}}},
+		{name: "unknown preset", preset: "emacs", wantErr: "unknown key preset"},
+		{name: "unknown action", bindings: map[string][]string{"launch": {"ctrl+l"}}, wantErr: "unknown action"},
+		{name: "empty keys", bindings: map[string][]string{"add": {}}, wantErr: "has no keys"},
+		{name: "duplicate screen key", bindings: map[string][]string{"add": {"ctrl+n"}}, wantErr: "bound to both"},
+		{name: "duplicate dialog key", bindings: map[string][]string{"toggle": {"enter"}}, wantErr: "bound to both"},
+		{name: "same key on screen and in dialogs", bindings: map[string][]string{"cancel": {"esc", "ctrl+x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := loadKeyMap(tt.preset, tt.bindings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadKeyMap error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadKeyMap: %v", err)
+			}
+			for name, keys := range tt.bindings {
+				for _, key := range keys {
+					if !km.is(action(name), key) {
+						t.Errorf("%s is not bound to %q", name, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDialogKeysAreNotScreenBindings(t *testing.T) {
+	km := defaultKeyMap()
+	for _, key := range []string{"enter", "esc", "tab"} {
+		if km.bound(key) {
+			t.Errorf("%q counts as a screen binding", key)
+		}
+	}
+	if !km.is(actConfirm, "enter") || !km.is(actCancel, "esc") {
+		t.Error("default dialog keys missing")
+	}
+}
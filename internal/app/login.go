@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	awsvc "aws-groups-manager/internal/aws"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type loginPromptMsg struct {
+	auth awsvc.DeviceAuthorization
+}
+
+type loginDoneMsg struct{}
+
+type mfaPromptMsg struct {
+	serial string
+	reply  chan<- string
+}
+
+type browserMsg struct {
+	err error
+}
+
+func (m model) ensureSessionCmd() tea.Cmd {
+	profile, region, auditLog, options, prompts, mfaPrompts := m.profile, m.region, m.auditLog, m.startCfg.Service, m.loginPrompts, m.mfaPrompts
+	return func() tea.Msg {
+		svc := awsvc.NewService(profile, region)
+		svc.SetAuditLog(auditLog)
+		svc.SetOptions(options)
+		svc.SetLoginPrompt(func(auth awsvc.DeviceAuthorization) {
+			prompts <- loginPromptMsg{auth: auth}
+		})
+		svc.SetMFAPrompt(func(serial string) (string, error) {
+			reply := make(chan string, 1)
+			mfaPrompts <- mfaPromptMsg{serial: serial, reply: reply}
+			if code := <-reply; code != "" {
+				return code, nil
+			}
+			return "", fmt.Errorf("MFA code for %s not entered", serial)
+		})
+		instances, err := svc.EnsureSession(context.Background())
+		return ensureSessionMsg{svc: svc, instances: instances, err: err}
+	}
+}
+
+func waitLoginPromptCmd(prompts <-chan loginPromptMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-prompts
+	}
+}
+
+func waitMFAPromptCmd(prompts <-chan mfaPromptMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-prompts
+	}
+}
+
+func waitLoginDoneCmd(done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-done
+		return loginDoneMsg{}
+	}
+}
+
+func (m *model) showLoginPrompt(msg loginPromptMsg) tea.Cmd {
+	m.login = msg.auth
+	m.loginCancel = msg.auth.Cancel
+	m.loginReturn = m.modal
+	m.modal = modalLogin
+	m.status = statusMessage{level: statusInfo, text: fmt.Sprintf("Waiting for SSO approval for %s", m.profile)}
+	return tea.Batch(waitLoginPromptCmd(m.loginPrompts), waitLoginDoneCmd(msg.auth.Done))
+}
+
+func (m *model) closeLoginPrompt() {
+	m.loginCancel = nil
+	if m.modal == modalLogin {
+		m.modal = m.loginReturn
+	}
+}
+
+func (m *model) showMFAPrompt(msg mfaPromptMsg) tea.Cmd {
+	m.mfa = msg
+	m.mfaReturn = m.modal
+	m.modal = modalMFAInput
+	m.mfaInput.SetValue("")
+	m.mfaInput.Focus()
+	m.status = statusMessage{level: statusInfo, text: "MFA code required for " + msg.serial}
+	return waitMFAPromptCmd(m.mfaPrompts)
+}
+
+func (m *model) handleMFAKey(msg tea.KeyMsg) tea.Cmd {
+	if key := msg.String(); m.keys.is(actConfirm, key) || m.keys.is(actCancel, key) {
+		code := ""
+		if m.keys.is(actConfirm, key) {
+			code = strings.TrimSpace(m.mfaInput.Value())
+		}
+		m.mfa.reply <- code
+		m.mfaInput.Blur()
+		m.modal = m.mfaReturn
+		return nil
+	}
+	var cmd tea.Cmd
+	m.mfaInput, cmd = m.mfaInput.Update(msg)
+	return cmd
+}
+
+func (m *model) handleLoginKey(key string) tea.Cmd {
+	switch {
+	case m.keys.is(actConfirm, key), key == "o":
+		return openBrowserCmd(m.loginURL())
+	case m.keys.is(actCancel, key):
+		if m.loginCancel != nil {
+			m.loginCancel()
+		}
+		m.closeLoginPrompt()
+		m.status = statusMessage{level: statusWarn, text: "SSO login cancelled"}
+	}
+	return nil
+}
+
+func (m model) loginURL() string {
+	if m.login.VerificationURIComplete != "" {
+		return m.login.VerificationURIComplete
+	}
+	return m.login.VerificationURI
+}
+
+func (m model) renderLoginModal() string {
+	return m.styles.Modal.Render(
+		m.styles.ModalTitle.Render("SSO Login") + "\n\n" +
+			fmt.Sprintf("Sign in to %s and approve this request:", m.login.StartURL) + "\n\n" +
+			m.loginURL() + "\n\n" +
+			"Code: " + m.styles.SelectedTitle.Render(m.login.UserCode) + "\n" +
+			"Expires: " + m.login.ExpiresAt.Format("15:04:05") + "\n\n" +
+			m.renderHint("Enter/o open browser | Esc cancel"),
+	)
+}
+
+func (m model) renderMFAModal() string {
+	return m.styles.Modal.Render(
+		m.styles.ModalTitle.Render("MFA Code") + "\n\n" +
+			"Enter the code for " + m.mfa.serial + "\n\n" +
+			m.mfaInput.View() + "\n\n" +
+			m.renderHint("Enter submit | Esc cancel"),
+	)
+}
+
+func openBrowserCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+		return browserMsg{err: cmd.Start()}
+	}
+}
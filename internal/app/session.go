@@ -0,0 +1,129 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"aws-groups-manager/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type sessionSavedMsg struct {
+	err error
+}
+
+type lastSession struct {
+	Context     string `json:"context,omitempty"`
+	Profile     string `json:"profile"`
+	Region      string `json:"region"`
+	InstanceARN string `json:"instance_arn,omitempty"`
+	GroupID     string `json:"group_id,omitempty"`
+	Tab         string `json:"tab,omitempty"`
+}
+
+func lastSessionPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "session.json"), nil
+}
+
+func loadLastSession(path string) lastSession {
+	var s lastSession
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lastSession{}
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return lastSession{}
+	}
+	return s
+}
+
+func restoreSession(cfg StartConfig, last lastSession) (StartConfig, lastSession) {
+	if cfg.Resume == config.ResumeOff || last.Profile == "" || last.Region == "" {
+		return cfg, lastSession{}
+	}
+	if cfg.Profile == "" && cfg.Region == "" {
+		cfg.Context = last.Context
+		cfg.Profile = last.Profile
+		cfg.Region = last.Region
+	}
+	if cfg.Profile != last.Profile || cfg.Region != last.Region {
+		return cfg, lastSession{}
+	}
+	if cfg.InstanceARN == "" && cfg.IdentityStoreID == "" {
+		cfg.InstanceARN = last.InstanceARN
+	}
+	if cfg.Resume != config.ResumeGroup || cfg.InstanceARN != last.InstanceARN {
+		return cfg, lastSession{}
+	}
+	return cfg, last
+}
+
+func (m *model) rememberSession() tea.Cmd {
+	if m.startCfg.Resume == config.ResumeOff || m.sessionPath == "" || m.instance.ARN == "" {
+		return nil
+	}
+
+	s := lastSession{Context: m.context, Profile: m.profile, Region: m.region, InstanceARN: m.instance.ARN}
+	if m.screen == screenGroupDetail {
+		s.GroupID = m.group.ID
+		s.Tab = "users"
+		if m.tab == tabAccounts {
+			s.Tab = "accounts"
+		}
+	}
+	return saveLastSessionCmd(m.sessionPath, s)
+}
+
+func (m *model) resumeGroup() tea.Cmd {
+	groupID := m.resume.GroupID
+	m.resume.GroupID = ""
+	if groupID == "" {
+		return nil
+	}
+
+	for _, g := range m.groups {
+		if g.ID == groupID {
+			m.status = statusMessage{level: statusInfo, text: "Resumed " + g.DisplayName}
+			return m.openGroupDetail(g)
+		}
+	}
+	m.resume = lastSession{}
+	return nil
+}
+
+func (m *model) resumeTab() tea.Cmd {
+	tab := m.resume.Tab
+	m.resume = lastSession{}
+	if tab != "accounts" || m.screen != screenGroupDetail || m.tab != tabUsers {
+		return nil
+	}
+	return m.toggleTab()
+}
+
+func saveLastSessionCmd(path string, s lastSession) tea.Cmd {
+	return func() tea.Msg {
+		return sessionSavedMsg{err: saveLastSession(path, s)}
+	}
+}
+
+func saveLastSession(path string, s lastSession) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,381 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"aws-groups-manager/internal/signing"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+type Targets struct {
+	GroupID          string `json:"group_id,omitempty"`
+	GroupName        string `json:"group_name,omitempty"`
+	UserID           string `json:"user_id,omitempty"`
+	MembershipID     string `json:"membership_id,omitempty"`
+	AccountID        string `json:"account_id,omitempty"`
+	PermissionSetARN string `json:"permission_set_arn,omitempty"`
+}
+
+type Record struct {
+	Seq         int64     `json:"seq,omitempty"`
+	Time        time.Time `json:"time"`
+	OSUser      string    `json:"os_user"`
+	Profile     string    `json:"profile"`
+	Region      string    `json:"region"`
+	InstanceARN string    `json:"instance_arn"`
+	Action      string    `json:"action"`
+	Targets     Targets   `json:"targets"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	PrevHash    string    `json:"prev_hash,omitempty"`
+	Hash        string    `json:"hash,omitempty"`
+}
+
+type Problem struct {
+	Line   int
+	Seq    int64
+	Reason string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d (seq %d): %s", p.Line, p.Seq, p.Reason)
+}
+
+type Log struct {
+	path string
+	key  ed25519.PrivateKey
+	mu   sync.Mutex
+}
+
+type checkpoint struct {
+	Seq       int64  `json:"seq"`
+	Hash      string `json:"hash"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "audit.jsonl"), nil
+}
+
+func Open() (*Log, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenPath(path)
+}
+
+func OpenPath(path string) (*Log, error) {
+	keyPath, err := signing.DefaultKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	key, err := signing.LoadOrCreateKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load signing key: %w", err)
+	}
+	return NewSignedLog(path, key), nil
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+func NewSignedLog(path string, key ed25519.PrivateKey) *Log {
+	return &Log{path: path, key: key}
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+func (l *Log) CheckpointPath() string {
+	return l.path + ".checkpoint"
+}
+
+func (l *Log) Append(rec Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rec.Time.IsZero() {
+		rec.Time = time.Now().UTC()
+	}
+	if rec.OSUser == "" {
+		rec.OSUser = OSUser()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("lock audit log: %w", err)
+	}
+	defer unlockFile(f)
+
+	prev, partial, err := lastRecord(f)
+	if err != nil {
+		return fmt.Errorf("read previous audit record: %w", err)
+	}
+
+	rec.Seq = prev.Seq + 1
+	rec.PrevHash = prev.Hash
+	rec.Hash, err = recordHash(rec)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	if partial {
+		line = append([]byte("\n"), line...)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return l.writeCheckpoint(rec)
+}
+
+func (l *Log) writeCheckpoint(rec Record) error {
+	if l.key == nil {
+		return nil
+	}
+
+	cp := checkpoint{Seq: rec.Seq, Hash: rec.Hash}
+	cp.PublicKey, cp.Signature = signing.Sign(l.key, checkpointPayload(cp))
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := l.CheckpointPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.CheckpointPath())
+}
+
+func (l *Log) verifyCheckpoint(records []Record, lines []int) []Problem {
+	if l.key == nil {
+		return nil
+	}
+
+	var last Record
+	lastLine := 0
+	if len(records) > 0 {
+		last = records[len(records)-1]
+		lastLine = lines[len(lines)-1]
+	}
+	data, err := os.ReadFile(l.CheckpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		if len(records) == 0 {
+			return nil
+		}
+		return []Problem{{Line: lastLine, Seq: last.Seq, Reason: "signed checkpoint is missing"}}
+	}
+	if err != nil {
+		return []Problem{{Line: lastLine, Seq: last.Seq, Reason: "signed checkpoint unreadable: " + err.Error()}}
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return []Problem{{Line: lastLine, Seq: last.Seq, Reason: "signed checkpoint unreadable: " + err.Error()}}
+	}
+	trusted := signing.PublicKey(l.key)
+	if cp.PublicKey != trusted || signing.Verify(trusted, cp.Signature, checkpointPayload(cp)) != nil {
+		return []Problem{{Line: lastLine, Seq: cp.Seq, Reason: "checkpoint signature does not match the local signing key"}}
+	}
+
+	for i, rec := range records {
+		if rec.Seq != cp.Seq {
+			continue
+		}
+		if rec.Hash != cp.Hash {
+			return []Problem{{Line: lines[i], Seq: rec.Seq, Reason: "record does not match the signed checkpoint (log rewritten)"}}
+		}
+		if i != len(records)-1 {
+			return []Problem{{Line: lines[i+1], Seq: records[i+1].Seq, Reason: "records after the signed checkpoint (appended outside aws-groups-manager)"}}
+		}
+		return nil
+	}
+	return []Problem{{Line: lastLine, Seq: last.Seq, Reason: fmt.Sprintf("signed checkpoint is at seq %d but the log ends at seq %d (records removed from the end)", cp.Seq, last.Seq)}}
+}
+
+func checkpointPayload(cp checkpoint) []byte {
+	return []byte(fmt.Sprintf("aws-groups-manager audit checkpoint\n%d\n%s\n", cp.Seq, cp.Hash))
+}
+
+func (l *Log) ReadAll() ([]Record, error) {
+	records, _, _, err := l.read()
+	return records, err
+}
+
+func (l *Log) Verify() (int, []Problem, error) {
+	records, lines, problems, err := l.read()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var prev Record
+	for i, rec := range records {
+		line := lines[i]
+
+		if rec.Hash == "" {
+			problems = append(problems, Problem{Line: line, Seq: rec.Seq, Reason: "record has no hash"})
+			prev = rec
+			continue
+		}
+
+		sum, err := recordHash(rec)
+		if err != nil {
+			return len(records), problems, err
+		}
+		if sum != rec.Hash {
+			problems = append(problems, Problem{Line: line, Seq: rec.Seq, Reason: "record content does not match its hash (edited)"})
+		}
+
+		if rec.PrevHash != prev.Hash {
+			problems = append(problems, Problem{Line: line, Seq: rec.Seq, Reason: "previous hash mismatch (record removed, inserted or reordered before this line)"})
+		}
+
+		if rec.Seq != prev.Seq+1 {
+			problems = append(problems, Problem{Line: line, Seq: rec.Seq, Reason: fmt.Sprintf("sequence gap: expected %d", prev.Seq+1)})
+		}
+
+		prev = rec
+	}
+
+	problems = append(problems, l.verifyCheckpoint(records, lines)...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return len(records), problems, nil
+}
+
+func (l *Log) read() ([]Record, []int, []Problem, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil, nil
+		}
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	records := make([]Record, 0, 64)
+	lines := make([]int, 0, 64)
+	problems := make([]Problem, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			problems = append(problems, Problem{Line: line, Reason: "unreadable record (corrupt or partially written): " + err.Error()})
+			continue
+		}
+		records = append(records, rec)
+		lines = append(lines, line)
+	}
+
+	return records, lines, problems, scanner.Err()
+}
+
+func recordHash(rec Record) (string, error) {
+	rec.Hash = ""
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func lastRecord(f *os.File) (Record, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	const chunk = 4096
+	size := info.Size()
+	partial := false
+	tail := make([]byte, 0, chunk)
+	for offset := size; offset > 0; {
+		n := min(int64(chunk), offset)
+		offset -= n
+
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+			return Record{}, false, err
+		}
+		if offset+n == size {
+			partial = buf[n-1] != '\n'
+		}
+		tail = append(buf, tail...)
+
+		lines := bytes.Split(tail, []byte("\n"))
+		first := 0
+		if offset > 0 {
+			first = 1
+		}
+		for i := len(lines) - 1; i >= first; i-- {
+			var rec Record
+			if len(lines[i]) > 0 && json.Unmarshal(lines[i], &rec) == nil {
+				return rec, partial, nil
+			}
+		}
+		tail = append(tail[:0:0], lines[0]...)
+	}
+
+	return Record{}, partial, nil
+}
+
+func OSUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestLog(t *testing.T) *Log {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSignedLog(filepath.Join(t.TempDir(), "audit.jsonl"), key)
+}
+
+func appendRecords(t *testing.T, log *Log, actions ...string) {
+	t.Helper()
+	for _, action := range actions {
+		if err := log.Append(Record{Action: action, Outcome: OutcomeSuccess}); err != nil {
+			t.Fatalf("Append(%s): %v", action, err)
+		}
+	}
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.SplitAfter(bytes.TrimRight(data, "\n"), []byte("\n"))
+}
+
+func writeLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+	data := bytes.Join(lines, nil)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func verifyReasons(t *testing.T, log *Log) []string {
+	t.Helper()
+	_, problems, err := log.Verify()
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	reasons := make([]string, 0, len(problems))
+	for _, p := range problems {
+		reasons = append(reasons, p.Reason)
+	}
+	return reasons
+}
+
+func expectProblem(t *testing.T, reasons []string, want string) {
+	t.Helper()
+	for _, reason := range reasons {
+		if strings.Contains(reason, want) {
+			return
+		}
+	}
+	t.Errorf("problems %q do not mention %q", reasons, want)
+}
+
+func TestAppendChainsRecords(t *testing.T) {
+	log := newTestLog(t)
+	appendRecords(t, log, "CreateGroup", "AddUserToGroup", "DeleteGroup")
+
+	records, err := log.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	for i, rec := range records {
+		if rec.Seq != int64(i+1) {
+			t.Errorf("record %d has seq %d", i, rec.Seq)
+		}
+		if i > 0 && rec.PrevHash != records[i-1].Hash {
+			t.Errorf("record %d does not chain to the previous hash", i)
+		}
+	}
+
+	count, problems, err := log.Verify()
+	if err != nil || count != 3 || len(problems) != 0 {
+		t.Fatalf("Verify = %d, %v, %v; want 3 clean records", count, problems, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, log *Log)
+		want   string
+	}{
+		{
+			name: "edited record",
+			tamper: func(t *testing.T, log *Log) {
+				lines := readLines(t, log.Path())
+				lines[1] = bytes.Replace(lines[1], []byte("AddUserToGroup"), []byte("RemoveUserFromGroup"), 1)
+				writeLines(t, log.Path(), lines)
+			},
+			want: "does not match its hash",
+		},
+		{
+			name: "removed record",
+			tamper: func(t *testing.T, log *Log) {
+				lines := readLines(t, log.Path())
+				writeLines(t, log.Path(), append(lines[:1:1], lines[2:]...))
+			},
+			want: "previous hash mismatch",
+		},
+		{
+			name: "truncated tail",
+			tamper: func(t *testing.T, log *Log) {
+				lines := readLines(t, log.Path())
+				writeLines(t, log.Path(), lines[:2])
+			},
+			want: "records removed from the end",
+		},
+		{
+			name: "rewritten chain",
+			tamper: func(t *testing.T, log *Log) {
+				records, err := log.ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				prev := Record{}
+				for _, rec := range records {
+					rec.Action = "CreateGroup"
+					rec.PrevHash = prev.Hash
+					if rec.Hash, err = recordHash(rec); err != nil {
+						t.Fatal(err)
+					}
+					line, _ := json.Marshal(rec)
+					buf.Write(append(line, '\n'))
+					prev = rec
+				}
+				if err := os.WriteFile(log.Path(), buf.Bytes(), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "does not match the signed checkpoint",
+		},
+		{
+			name: "deleted checkpoint",
+			tamper: func(t *testing.T, log *Log) {
+				if err := os.Remove(log.CheckpointPath()); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "checkpoint is missing",
+		},
+		{
+			name: "checkpoint from another key",
+			tamper: func(t *testing.T, log *Log) {
+				_, other, _ := ed25519.GenerateKey(rand.Reader)
+				records, _ := log.ReadAll()
+				if err := NewSignedLog(log.Path(), other).writeCheckpoint(records[len(records)-1]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "does not match the local signing key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newTestLog(t)
+			appendRecords(t, log, "CreateGroup", "AddUserToGroup", "DeleteGroup")
+			tt.tamper(t, log)
+			expectProblem(t, verifyReasons(t, log), tt.want)
+		})
+	}
+}
+
+func TestAppendFromSeparateHandles(t *testing.T) {
+	first := newTestLog(t)
+	second := NewSignedLog(first.Path(), first.key)
+
+	var wg sync.WaitGroup
+	for _, log := range []*Log{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if err := log.Append(Record{Action: "AddUserToGroup", Outcome: OutcomeSuccess}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	count, problems, err := first.Verify()
+	if err != nil || count != 50 || len(problems) != 0 {
+		t.Fatalf("Verify = %d, %v, %v; want 50 clean records", count, problems, err)
+	}
+}
+
+func TestAppendAfterPartialWrite(t *testing.T) {
+	log := newTestLog(t)
+	appendRecords(t, log, "CreateGroup", "AddUserToGroup")
+
+	f, err := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"seq":3,"action":"Delete`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	reasons := verifyReasons(t, log)
+	if len(reasons) != 1 {
+		t.Fatalf("problems = %q, want only the unreadable line", reasons)
+	}
+	expectProblem(t, reasons, "unreadable record")
+
+	appendRecords(t, log, "DeleteGroup")
+
+	records, err := log.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(records) != 3 || records[2].Seq != 3 || records[2].PrevHash != records[1].Hash {
+		t.Fatalf("records after partial write = %+v", records)
+	}
+
+	_, problems, err := log.Verify()
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line != 3 {
+		t.Fatalf("problems = %v, want only line 3", problems)
+	}
+}
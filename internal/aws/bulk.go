@@ -0,0 +1,246 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	identitytypes "github.com/aws/aws-sdk-go-v2/service/identitystore/types"
+)
+
+type ChangeResult struct {
+	Action string
+	Target string
+	Err    error
+}
+
+type ChangeReport struct {
+	Results []ChangeResult
+}
+
+func (r *ChangeReport) add(action, target string, err error) {
+	r.Results = append(r.Results, ChangeResult{Action: action, Target: target, Err: err})
+}
+
+func (r ChangeReport) Succeeded() int {
+	count := 0
+	for _, res := range r.Results {
+		if res.Err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func (r ChangeReport) Failed() int {
+	return len(r.Results) - r.Succeeded()
+}
+
+func (r ChangeReport) Summary() string {
+	return fmt.Sprintf("%d succeeded, %d failed", r.Succeeded(), r.Failed())
+}
+
+type CloneOptions struct {
+	Members     bool
+	Assignments bool
+}
+
+func (s *Service) CloneGroup(ctx context.Context, sourceID, name string, opts CloneOptions) (string, ChangeReport, error) {
+	report := ChangeReport{}
+
+	var members []GroupUser
+	if opts.Members {
+		var err error
+		members, err = s.ListGroupUsers(ctx, sourceID)
+		if err != nil {
+			return "", report, fmt.Errorf("list source members: %w", err)
+		}
+	}
+
+	var assignments []Assignment
+	if opts.Assignments {
+		var err error
+		assignments, err = s.ListGroupAssignments(ctx, sourceID)
+		if err != nil {
+			return "", report, fmt.Errorf("list source assignments: %w", err)
+		}
+	}
+
+	groupID, err := s.CreateGroup(ctx, name)
+	if err != nil {
+		return "", report, err
+	}
+	report.add("CreateGroup", name, nil)
+
+	for _, member := range members {
+		_, err := s.AddUserToGroup(ctx, groupID, member.UserID)
+		report.add("AddUserToGroup", memberLabel(member), err)
+	}
+
+	for _, a := range assignments {
+		err := s.CreateAssignment(ctx, groupID, a.AccountID, a.PermissionSetARN)
+		report.add("CreateAssignment", assignmentLabel(a), err)
+	}
+
+	return groupID, report, nil
+}
+
+func memberLabel(member GroupUser) string {
+	if member.DisplayName != "" && member.DisplayName != member.UserID {
+		return member.DisplayName + " (" + member.UserID + ")"
+	}
+	return member.UserID
+}
+
+func assignmentLabel(a Assignment) string {
+	name := a.PermissionSetName
+	if name == "" {
+		parts := strings.Split(a.PermissionSetARN, "/")
+		name = parts[len(parts)-1]
+	}
+	return name + " on " + a.AccountID
+}
+
+func (s *Service) TransferUsers(ctx context.Context, users []GroupUser, sourceGroupID, targetGroupID string, move bool) ChangeReport {
+	report := ChangeReport{}
+
+	for _, user := range users {
+		label := memberLabel(user)
+
+		_, err := s.AddUserToGroup(ctx, targetGroupID, user.UserID)
+		if err != nil && isConflict(err) {
+			report.add("AddUserToGroup", label+" (already a member)", nil)
+		} else {
+			report.add("AddUserToGroup", label, err)
+			if err != nil {
+				continue
+			}
+		}
+
+		if move {
+			err := s.RemoveUserFromGroup(ctx, sourceGroupID, user.UserID, user.MembershipID)
+			report.add("RemoveUserFromGroup", label, err)
+		}
+	}
+
+	return report
+}
+
+func isConflict(err error) bool {
+	var conflict *identitytypes.ConflictException
+	return errors.As(err, &conflict)
+}
+
+func (s *Service) MergeGroups(ctx context.Context, sources []Group, target Group) (ChangeReport, error) {
+	report := ChangeReport{}
+
+	targetUsers, err := s.ListGroupUsers(ctx, target.ID)
+	if err != nil {
+		return report, fmt.Errorf("list members of %s: %w", target.DisplayName, err)
+	}
+	targetAssignments, err := s.ListGroupAssignments(ctx, target.ID)
+	if err != nil {
+		return report, fmt.Errorf("list assignments of %s: %w", target.DisplayName, err)
+	}
+
+	haveUser := make(map[string]bool, len(targetUsers))
+	for _, user := range targetUsers {
+		haveUser[user.UserID] = true
+	}
+	haveAssignment := make(map[string]bool, len(targetAssignments))
+	for _, a := range targetAssignments {
+		haveAssignment[a.AccountID+"|"+a.PermissionSetARN] = true
+	}
+
+	for _, source := range sources {
+		users, err := s.ListGroupUsers(ctx, source.ID)
+		if err != nil {
+			report.add("ListGroupMemberships", source.DisplayName, err)
+			continue
+		}
+		for _, user := range users {
+			if haveUser[user.UserID] {
+				continue
+			}
+			_, err := s.AddUserToGroup(ctx, target.ID, user.UserID)
+			report.add("AddUserToGroup", memberLabel(user)+" from "+source.DisplayName, err)
+			if err == nil {
+				haveUser[user.UserID] = true
+			}
+		}
+
+		assignments, err := s.ListGroupAssignments(ctx, source.ID)
+		if err != nil {
+			report.add("ListAccountAssignmentsForPrincipal", source.DisplayName, err)
+			continue
+		}
+		for _, a := range assignments {
+			key := a.AccountID + "|" + a.PermissionSetARN
+			if haveAssignment[key] {
+				continue
+			}
+			err := s.CreateAssignment(ctx, target.ID, a.AccountID, a.PermissionSetARN)
+			report.add("CreateAssignment", assignmentLabel(a)+" from "+source.DisplayName, err)
+			if err == nil {
+				haveAssignment[key] = true
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) DeleteGroups(ctx context.Context, groups []Group) ChangeReport {
+	report := ChangeReport{}
+	for _, group := range groups {
+		err := s.DeleteGroup(ctx, group.ID)
+		report.add("DeleteGroup", group.DisplayName, err)
+	}
+	return report
+}
+
+func (s *Service) AddUserToGroups(ctx context.Context, user User, groups []UserMembership) ChangeReport {
+	report := ChangeReport{}
+	for _, g := range groups {
+		_, err := s.AddUserToGroup(ctx, g.GroupID, user.ID)
+		report.add("AddUserToGroup", g.GroupName+" ("+g.GroupID+")", err)
+	}
+	return report
+}
+
+type UnresolvedUser struct {
+	Query string
+	Err   error
+}
+
+func (s *Service) ResolveUsers(ctx context.Context, queries []string) ([]User, []UnresolvedUser) {
+	resolved := make([]User, 0, len(queries))
+	unresolved := make([]UnresolvedUser, 0)
+	seen := make(map[string]bool, len(queries))
+
+	for _, query := range queries {
+		user, err := s.FindUser(ctx, query)
+		if err != nil {
+			unresolved = append(unresolved, UnresolvedUser{Query: query, Err: err})
+			continue
+		}
+		if seen[user.ID] {
+			continue
+		}
+		seen[user.ID] = true
+		resolved = append(resolved, user)
+	}
+
+	return resolved, unresolved
+}
+
+func (s *Service) AddUsersToGroup(ctx context.Context, groupID string, users []User) ChangeReport {
+	report := ChangeReport{}
+	for _, user := range users {
+		_, err := s.AddUserToGroup(ctx, groupID, user.ID)
+		report.add("AddUserToGroup", cmp.Or(user.UserName, user.DisplayName, user.ID), err)
+	}
+	return report
+}
@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+)
+
+type GroupComparison struct {
+	A Group
+	B Group
+
+	OnlyAUsers []GroupUser
+	OnlyBUsers []GroupUser
+	BothUsers  []GroupUser
+
+	OnlyAAssignments []Assignment
+	OnlyBAssignments []Assignment
+	BothAssignments  []Assignment
+}
+
+func (s *Service) CompareGroups(ctx context.Context, a, b Group, permissionSets []PermissionSet) (GroupComparison, error) {
+	comparison := GroupComparison{A: a, B: b}
+
+	usersA, err := s.ListGroupUsers(ctx, a.ID)
+	if err != nil {
+		return comparison, fmt.Errorf("list members of %s: %w", a.DisplayName, err)
+	}
+	usersB, err := s.ListGroupUsers(ctx, b.ID)
+	if err != nil {
+		return comparison, fmt.Errorf("list members of %s: %w", b.DisplayName, err)
+	}
+
+	assignA, err := s.ListGroupAssignments(ctx, a.ID)
+	if err != nil {
+		return comparison, fmt.Errorf("list assignments of %s: %w", a.DisplayName, err)
+	}
+	assignB, err := s.ListGroupAssignments(ctx, b.ID)
+	if err != nil {
+		return comparison, fmt.Errorf("list assignments of %s: %w", b.DisplayName, err)
+	}
+
+	names := make(map[string]string, len(permissionSets))
+	for _, ps := range permissionSets {
+		names[ps.ARN] = ps.Name
+	}
+	for i := range assignA {
+		assignA[i].PermissionSetName = names[assignA[i].PermissionSetARN]
+	}
+	for i := range assignB {
+		assignB[i].PermissionSetName = names[assignB[i].PermissionSetARN]
+	}
+
+	comparison.OnlyAUsers, comparison.OnlyBUsers, comparison.BothUsers = diffUsers(usersA, usersB)
+	comparison.OnlyAAssignments, comparison.OnlyBAssignments, comparison.BothAssignments = diffAssignments(assignA, assignB)
+	return comparison, nil
+}
+
+func (s *Service) SyncGroups(ctx context.Context, comparison GroupComparison, fromA, prune bool) ChangeReport {
+	target := comparison.B
+	addUsers, extraUsers := comparison.OnlyAUsers, comparison.OnlyBUsers
+	addAssignments, extraAssignments := comparison.OnlyAAssignments, comparison.OnlyBAssignments
+	if !fromA {
+		target = comparison.A
+		addUsers, extraUsers = comparison.OnlyBUsers, comparison.OnlyAUsers
+		addAssignments, extraAssignments = comparison.OnlyBAssignments, comparison.OnlyAAssignments
+	}
+
+	report := ChangeReport{}
+	for _, user := range addUsers {
+		_, err := s.AddUserToGroup(ctx, target.ID, user.UserID)
+		report.add("AddUserToGroup", memberLabel(user), err)
+	}
+	for _, a := range addAssignments {
+		err := s.CreateAssignment(ctx, target.ID, a.AccountID, a.PermissionSetARN)
+		report.add("CreateAssignment", assignmentLabel(a), err)
+	}
+
+	if !prune {
+		return report
+	}
+
+	for _, user := range extraUsers {
+		err := s.RemoveUserFromGroup(ctx, target.ID, user.UserID, user.MembershipID)
+		report.add("RemoveUserFromGroup", memberLabel(user), err)
+	}
+	for _, a := range extraAssignments {
+		err := s.DeleteAssignment(ctx, target.ID, a.AccountID, a.PermissionSetARN)
+		report.add("DeleteAssignment", assignmentLabel(a), err)
+	}
+
+	return report
+}
+
+func (c GroupComparison) SyncRemovals(fromA bool) []string {
+	extraUsers, extraAssignments := c.OnlyBUsers, c.OnlyBAssignments
+	if !fromA {
+		extraUsers, extraAssignments = c.OnlyAUsers, c.OnlyAAssignments
+	}
+	removals := make([]string, 0, len(extraUsers)+len(extraAssignments))
+	for _, user := range extraUsers {
+		removals = append(removals, "member: "+memberLabel(user))
+	}
+	for _, a := range extraAssignments {
+		removals = append(removals, "assignment: "+assignmentLabel(a))
+	}
+	return removals
+}
+
+func diffUsers(a, b []GroupUser) (onlyA, onlyB, both []GroupUser) {
+	inB := make(map[string]bool, len(b))
+	for _, user := range b {
+		inB[user.UserID] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, user := range a {
+		inA[user.UserID] = true
+		if inB[user.UserID] {
+			both = append(both, user)
+		} else {
+			onlyA = append(onlyA, user)
+		}
+	}
+	for _, user := range b {
+		if !inA[user.UserID] {
+			onlyB = append(onlyB, user)
+		}
+	}
+	return onlyA, onlyB, both
+}
+
+func diffAssignments(a, b []Assignment) (onlyA, onlyB, both []Assignment) {
+	key := func(x Assignment) string { return x.AccountID + "|" + x.PermissionSetARN }
+
+	inB := make(map[string]bool, len(b))
+	for _, x := range b {
+		inB[key(x)] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, x := range a {
+		inA[key(x)] = true
+		if inB[key(x)] {
+			both = append(both, x)
+		} else {
+			onlyA = append(onlyA, x)
+		}
+	}
+	for _, x := range b {
+		if !inA[key(x)] {
+			onlyB = append(onlyB, x)
+		}
+	}
+	return onlyA, onlyB, both
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"aws-groups-manager/internal/profiles"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const roleSessionName = "aws-groups-manager"
+
+func (s *Service) SetMFAPrompt(prompt func(serial string) (string, error)) {
+	s.mfaPrompt = prompt
+}
+
+func (s *Service) mfaToken(serial string) func() (string, error) {
+	return func() (string, error) {
+		if s.mfaPrompt == nil {
+			return "", fmt.Errorf("MFA code required for %s", serial)
+		}
+		return s.mfaPrompt(serial)
+	}
+}
+
+func (s *Service) profileMFA(o *stscreds.AssumeRoleOptions) {
+	if o.SerialNumber != nil {
+		o.TokenProvider = s.mfaToken(*o.SerialNumber)
+	}
+}
+
+func (s *Service) assumeRole(cfg awssdk.Config) awssdk.CredentialsProvider {
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), s.options.AssumeRoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+		o.Duration = time.Hour
+		if s.options.ExternalID != "" {
+			o.ExternalID = awssdk.String(s.options.ExternalID)
+		}
+		if s.options.MFASerial != "" {
+			o.SerialNumber = awssdk.String(s.options.MFASerial)
+			o.TokenProvider = s.mfaToken(s.options.MFASerial)
+		}
+	})
+	return awssdk.NewCredentialsCache(provider)
+}
+
+func ssoProfile(name string) (profiles.Profile, bool, error) {
+	if name == profiles.NoProfile {
+		return profiles.Profile{}, false, nil
+	}
+	cfg, err := profiles.Load()
+	if err != nil {
+		return profiles.Profile{}, false, err
+	}
+	p, ok := cfg.SSOProfile(name)
+	return p, ok, nil
+}
@@ -1,5 +1,73 @@
 package aws
 
-import "errors"
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+	ssooidctypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/smithy-go"
+)
 
 var ErrOrganizationsAccessDenied = errors.New("organizations access denied")
+
+var ErrUserNotFound = errors.New("user not found")
+
+var ErrMultipleInstances = errors.New("multiple Identity Center instances")
+
+func IsThrottling(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ThrottlingException", "Throttling", "TooManyRequestsException", "RequestLimitExceeded":
+			return true
+		}
+	}
+
+	return strings.Contains(strings.ToLower(err.Error()), "rate exceeded")
+}
+
+func isAuthExpired(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var invalidToken *ssocreds.InvalidTokenError
+	var unauthorized *ssotypes.UnauthorizedException
+	var invalidGrant *ssooidctypes.InvalidGrantException
+	var expiredToken *ssooidctypes.ExpiredTokenException
+	var invalidClient *ssooidctypes.InvalidClientException
+	switch {
+	case errors.As(err, &invalidToken), errors.As(err, &unauthorized), errors.As(err, &invalidGrant),
+		errors.As(err, &expiredToken), errors.As(err, &invalidClient):
+		return true
+	case missingCachedToken(err):
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ExpiredToken", "ExpiredTokenException":
+			return true
+		}
+	}
+
+	return false
+}
+
+func missingCachedToken(err error) bool {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || !errors.Is(pathErr, fs.ErrNotExist) {
+		return false
+	}
+	cached, cacheErr := ssocreds.StandardCachedTokenFilepath("")
+	return cacheErr == nil && filepath.Dir(pathErr.Path) == filepath.Dir(cached)
+}
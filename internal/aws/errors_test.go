@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+)
+
+func TestIsAuthExpired(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cachePath, err := ssocreds.StandardCachedTokenFilepath("my-sso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, missingToken := os.ReadFile(cachePath)
+	_, missingOther := os.ReadFile(filepath.Join(t.TempDir(), "keys.json"))
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"missing cached token", fmt.Errorf("failed to read cached SSO token file, %w", missingToken), true},
+		{"legacy invalid token", &ssocreds.InvalidTokenError{Err: missingToken}, true},
+		{"unrelated missing file", fmt.Errorf("load keymap: %w", missingOther), false},
+		{"plain error", fmt.Errorf("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAuthExpired(tt.err); got != tt.want {
+				t.Errorf("isAuthExpired(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidctypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+const (
+	loginClientName   = "aws-groups-manager"
+	deviceCodeGrant   = "urn:ietf:params:oauth:grant-type:device_code"
+	defaultLoginScope = "sso:account:access"
+)
+
+type DeviceAuthorization struct {
+	StartURL                string
+	VerificationURI         string
+	VerificationURIComplete string
+	UserCode                string
+	ExpiresAt               time.Time
+	Done                    <-chan struct{}
+	Cancel                  context.CancelFunc
+}
+
+type cachedToken struct {
+	StartURL              string `json:"startUrl"`
+	Region                string `json:"region"`
+	AccessToken           string `json:"accessToken"`
+	ExpiresAt             string `json:"expiresAt"`
+	ClientID              string `json:"clientId,omitempty"`
+	ClientSecret          string `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt string `json:"registrationExpiresAt,omitempty"`
+	RefreshToken          string `json:"refreshToken,omitempty"`
+}
+
+func (s *Service) SetLoginPrompt(prompt func(DeviceAuthorization)) {
+	s.loginPrompt = prompt
+}
+
+func (s *Service) loginSSO(ctx context.Context) error {
+	p, ok, err := ssoProfile(s.profile)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("profile %s does not use IAM Identity Center", s.profile)
+	}
+	if p.SSOStartURL == "" || p.SSORegion == "" {
+		return fmt.Errorf("profile %s needs sso_start_url and sso_region", s.profile)
+	}
+	if s.loginPrompt == nil {
+		return fmt.Errorf("SSO login required for profile %s", s.profile)
+	}
+
+	client := ssooidc.NewFromConfig(s.awsCfg, func(o *ssooidc.Options) {
+		o.Region = p.SSORegion
+		o.APIOptions = nil
+	})
+
+	register := &ssooidc.RegisterClientInput{
+		ClientName: awssdk.String(loginClientName),
+		ClientType: awssdk.String("public"),
+	}
+	if p.SSOSession != "" {
+		register.Scopes = []string{defaultLoginScope}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	registration, err := client.RegisterClient(ctx, register)
+	if err != nil {
+		return fmt.Errorf("register client: %w", err)
+	}
+
+	device, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     awssdk.String(p.SSOStartURL),
+	})
+	if err != nil {
+		return fmt.Errorf("start device authorization: %w", err)
+	}
+
+	s.loginPrompt(DeviceAuthorization{
+		StartURL:                p.SSOStartURL,
+		VerificationURI:         value(device.VerificationUri),
+		VerificationURIComplete: value(device.VerificationUriComplete),
+		UserCode:                value(device.UserCode),
+		ExpiresAt:               time.Now().Add(time.Duration(device.ExpiresIn) * time.Second),
+		Done:                    ctx.Done(),
+		Cancel:                  cancel,
+	})
+
+	token, err := pollDeviceToken(ctx, client, registration, device)
+	if err != nil {
+		return err
+	}
+
+	cached := cachedToken{
+		StartURL:    p.SSOStartURL,
+		Region:      p.SSORegion,
+		AccessToken: value(token.AccessToken),
+		ExpiresAt:   rfc3339(time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)),
+	}
+	cacheKey := p.SSOStartURL
+	if p.SSOSession != "" {
+		cacheKey = p.SSOSession
+		cached.ClientID = value(registration.ClientId)
+		cached.ClientSecret = value(registration.ClientSecret)
+		cached.RegistrationExpiresAt = rfc3339(time.Unix(registration.ClientSecretExpiresAt, 0))
+		cached.RefreshToken = value(token.RefreshToken)
+	}
+	return writeCachedToken(cacheKey, cached)
+}
+
+func pollDeviceToken(ctx context.Context, client *ssooidc.Client, registration *ssooidc.RegisterClientOutput, device *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, time.Duration(device.ExpiresIn)*time.Second, fmt.Errorf("SSO login was not approved before the code expired"))
+	defer cancel()
+
+	for {
+		token, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     registration.ClientId,
+			ClientSecret: registration.ClientSecret,
+			DeviceCode:   device.DeviceCode,
+			GrantType:    awssdk.String(deviceCodeGrant),
+		})
+		if err == nil {
+			return token, nil
+		}
+
+		var pending *ssooidctypes.AuthorizationPendingException
+		var slowDown *ssooidctypes.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+		case errors.As(err, &slowDown):
+			interval += 5 * time.Second
+		case ctx.Err() != nil:
+			return nil, context.Cause(ctx)
+		default:
+			return nil, fmt.Errorf("create token: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func writeCachedToken(key string, token cachedToken) error {
+	path, err := ssocreds.StandardCachedTokenFilepath(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func rfc3339(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+)
+
+type OffboardPlan struct {
+	User        User
+	Memberships []UserMembership
+	Assignments []Assignment
+	Inherited   []Assignment
+}
+
+func (s *Service) PlanOffboard(ctx context.Context, user User, groupNames map[string]string) (OffboardPlan, error) {
+	plan := OffboardPlan{User: user}
+
+	memberships, err := s.ListUserMemberships(ctx, user.ID, groupNames)
+	if err != nil {
+		return plan, fmt.Errorf("list group memberships: %w", err)
+	}
+	plan.Memberships = memberships
+
+	plan.Assignments, plan.Inherited, err = s.ListUserAccess(ctx, user.ID)
+	if err != nil {
+		return plan, fmt.Errorf("list assignments: %w", err)
+	}
+
+	return plan, nil
+}
+
+func (s *Service) ExecuteOffboard(ctx context.Context, plan OffboardPlan) ChangeReport {
+	report := ChangeReport{}
+
+	for _, m := range plan.Memberships {
+		err := s.RemoveUserFromGroup(ctx, m.GroupID, plan.User.ID, m.MembershipID)
+		report.add("RemoveUserFromGroup", m.GroupName+" ("+m.GroupID+")", err)
+	}
+
+	for _, a := range plan.Assignments {
+		err := s.DeleteUserAssignment(ctx, plan.User.ID, a.AccountID, a.PermissionSetARN)
+		report.add("DeleteAssignment", assignmentLabel(a), err)
+	}
+
+	return report
+}
@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func (s *Service) addReauthMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ReauthenticateSSO", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		generation := s.loginGeneration()
+		out, metadata, err := next.HandleInitialize(ctx, in)
+		if !s.sso || s.loginPrompt == nil || !isAuthExpired(err) {
+			return out, metadata, err
+		}
+		if loginErr := s.relogin(ctx, generation); loginErr != nil {
+			return out, metadata, fmt.Errorf("%w; SSO login failed: %w", err, loginErr)
+		}
+		return next.HandleInitialize(ctx, in)
+	}), middleware.Before)
+}
+
+func (s *Service) loginGeneration() int {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	return s.loginGen
+}
+
+func (s *Service) relogin(ctx context.Context, seen int) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	if s.loginGen != seen {
+		return s.loginErr
+	}
+
+	s.loginErr = s.loginSSO(ctx)
+	s.loginGen++
+	if cache, ok := s.awsCfg.Credentials.(*awssdk.CredentialsCache); ok && s.loginErr == nil {
+		cache.Invalidate()
+	}
+	return s.loginErr
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	accounttypes "github.com/aws/aws-sdk-go-v2/service/account/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+)
+
+const (
+	AllRegions = "all"
+
+	discoveryRegion = "us-east-1"
+)
+
+var DefaultRegions = []string{
+	"af-south-1",
+	"ap-east-1",
+	"ap-east-2",
+	"ap-south-1",
+	"ap-south-2",
+	"ap-southeast-1",
+	"ap-southeast-2",
+	"ap-southeast-3",
+	"ap-southeast-4",
+	"ap-southeast-5",
+	"ap-southeast-6",
+	"ap-southeast-7",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-northeast-3",
+	"ca-central-1",
+	"ca-west-1",
+	"eu-central-1",
+	"eu-central-2",
+	"eu-west-1",
+	"eu-west-2",
+	"eu-west-3",
+	"eu-north-1",
+	"eu-south-1",
+	"eu-south-2",
+	"il-central-1",
+	"me-central-1",
+	"me-south-1",
+	"mx-central-1",
+	"sa-east-1",
+	"us-east-1",
+	"us-east-2",
+	"us-west-1",
+	"us-west-2",
+}
+
+func (s *Service) Regions() []string {
+	return s.regions
+}
+
+func (s *Service) EnabledRegions(ctx context.Context) ([]string, error) {
+	if s.accountClient == nil {
+		if err := s.loadClients(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	regions := make([]string, 0, len(DefaultRegions))
+	pager := account.NewListRegionsPaginator(s.accountClient, &account.ListRegionsInput{
+		RegionOptStatusContains: []accounttypes.RegionOptStatus{accounttypes.RegionOptStatusEnabled, accounttypes.RegionOptStatusEnabledByDefault},
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page.Regions {
+			regions = append(regions, value(r.RegionName))
+		}
+	}
+
+	sort.Strings(regions)
+	s.regions = regions
+	return regions, nil
+}
+
+func (s *Service) discoverInstances(ctx context.Context) ([]Instance, error) {
+	regions, err := s.EnabledRegions(ctx)
+	if err != nil || len(regions) == 0 {
+		regions = DefaultRegions
+		s.regions = regions
+	}
+
+	found := make([][]Instance, len(regions))
+	errs := make([]error, len(regions))
+	if err := s.parallel(ctx, len(regions), func(ctx context.Context, i int) error {
+		client := ssoadmin.NewFromConfig(s.awsCfg, func(o *ssoadmin.Options) {
+			o.Region = regions[i]
+		})
+		found[i], errs[i] = listInstancesWith(ctx, client, regions[i])
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	instances := make([]Instance, 0, 4)
+	for _, batch := range found {
+		instances = append(instances, batch...)
+	}
+	if len(instances) > 0 {
+		return instances, nil
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("no Identity Center instances found in %d regions (first error in %s: %w)", len(regions), regions[i], err)
+		}
+	}
+	return nil, nil
+}
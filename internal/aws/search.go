@@ -0,0 +1,266 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	ssoadmintypes "github.com/aws/aws-sdk-go-v2/service/ssoadmin/types"
+)
+
+const (
+	KindGroup         = "group"
+	KindUser          = "user"
+	KindAccount       = "account"
+	KindPermissionSet = "permission set"
+)
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+type SearchIndex struct {
+	Groups         []Group
+	Accounts       []Account
+	PermissionSets []PermissionSet
+}
+
+type SearchResult struct {
+	Kind          string
+	Score         int
+	Group         Group
+	User          User
+	Account       Account
+	PermissionSet PermissionSet
+}
+
+func (r SearchResult) Title() string {
+	switch r.Kind {
+	case KindGroup:
+		return r.Group.DisplayName
+	case KindUser:
+		return r.User.DisplayName
+	case KindAccount:
+		return cmp.Or(r.Account.Name, r.Account.ID)
+	default:
+		return r.PermissionSet.Name
+	}
+}
+
+func (r SearchResult) Detail() string {
+	switch r.Kind {
+	case KindGroup:
+		return r.Group.ID
+	case KindUser:
+		return cmp.Or(r.User.Email, r.User.UserName) + " | " + r.User.ID
+	case KindAccount:
+		return r.Account.ID
+	default:
+		return r.PermissionSet.ARN
+	}
+}
+
+type PrincipalAssignment struct {
+	AccountID        string
+	PermissionSetARN string
+	PrincipalType    string
+	PrincipalID      string
+}
+
+func (s *Service) LoadSearchIndex(ctx context.Context) (SearchIndex, error) {
+	groups, err := s.ListGroups(ctx)
+	if err != nil {
+		return SearchIndex{}, err
+	}
+	permissionSets, err := s.ListPermissionSets(ctx)
+	if err != nil {
+		return SearchIndex{}, err
+	}
+	accounts, err := s.ListAccounts(ctx)
+	if err != nil && !errors.Is(err, ErrOrganizationsAccessDenied) {
+		return SearchIndex{}, err
+	}
+
+	return SearchIndex{Groups: groups, Accounts: accounts, PermissionSets: permissionSets}, nil
+}
+
+func (index SearchIndex) Search(query string) []SearchResult {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	needle := strings.ToLower(query)
+
+	results := make([]SearchResult, 0, 32)
+	for _, g := range index.Groups {
+		if score := bestScore(needle, g.DisplayName, g.ID, g.Description); score > 0 {
+			results = append(results, SearchResult{Kind: KindGroup, Score: score, Group: g})
+		}
+	}
+	knownAccount := false
+	for _, a := range index.Accounts {
+		if score := bestScore(needle, a.Name, a.ID, a.Email); score > 0 {
+			results = append(results, SearchResult{Kind: KindAccount, Score: score, Account: a})
+			knownAccount = knownAccount || a.ID == query
+		}
+	}
+	if !knownAccount && accountIDPattern.MatchString(query) {
+		results = append(results, SearchResult{Kind: KindAccount, Score: scoreExact, Account: Account{ID: query}})
+	}
+	for _, ps := range index.PermissionSets {
+		if score := bestScore(needle, ps.Name, ps.ARN); score > 0 {
+			results = append(results, SearchResult{Kind: KindPermissionSet, Score: score, PermissionSet: ps})
+		}
+	}
+
+	return results
+}
+
+func UserSearchResults(query string, users []User) []SearchResult {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	results := make([]SearchResult, 0, len(users))
+	for _, u := range users {
+		results = append(results, SearchResult{Kind: KindUser, Score: max(1, bestScore(needle, u.UserName, u.Email, u.DisplayName, u.ID)), User: u})
+	}
+	return results
+}
+
+func RankSearchResults(results []SearchResult, limit int) []SearchResult {
+	ranked := append([]SearchResult(nil), results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+func (s *Service) ListAccountAccess(ctx context.Context, accountID string) ([]PrincipalAssignment, error) {
+	arns := make([]string, 0, 32)
+	pager := ssoadmin.NewListPermissionSetsProvisionedToAccountPaginator(s.ssoAdminClient, &ssoadmin.ListPermissionSetsProvisionedToAccountInput{
+		InstanceArn: &s.instanceARN,
+		AccountId:   &accountID,
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, page.PermissionSets...)
+	}
+
+	found := make([][]PrincipalAssignment, len(arns))
+	err := s.parallel(ctx, len(arns), func(ctx context.Context, i int) error {
+		var err error
+		found[i], err = s.listAccountAssignments(ctx, accountID, arns[i])
+		return err
+	})
+	return flattenAccess(found), err
+}
+
+func (s *Service) ListPermissionSetAccess(ctx context.Context, permissionSetARN string) ([]PrincipalAssignment, error) {
+	accountIDs := make([]string, 0, 32)
+	pager := ssoadmin.NewListAccountsForProvisionedPermissionSetPaginator(s.ssoAdminClient, &ssoadmin.ListAccountsForProvisionedPermissionSetInput{
+		InstanceArn:      &s.instanceARN,
+		PermissionSetArn: &permissionSetARN,
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		accountIDs = append(accountIDs, page.AccountIds...)
+	}
+
+	found := make([][]PrincipalAssignment, len(accountIDs))
+	err := s.parallel(ctx, len(accountIDs), func(ctx context.Context, i int) error {
+		var err error
+		found[i], err = s.listAccountAssignments(ctx, accountIDs[i], permissionSetARN)
+		return err
+	})
+	return flattenAccess(found), err
+}
+
+func flattenAccess(found [][]PrincipalAssignment) []PrincipalAssignment {
+	access := make([]PrincipalAssignment, 0, len(found))
+	for _, batch := range found {
+		access = append(access, batch...)
+	}
+	return access
+}
+
+func (s *Service) listAccountAssignments(ctx context.Context, accountID, permissionSetARN string) ([]PrincipalAssignment, error) {
+	assignments := make([]PrincipalAssignment, 0, 8)
+	pager := ssoadmin.NewListAccountAssignmentsPaginator(s.ssoAdminClient, &ssoadmin.ListAccountAssignmentsInput{
+		InstanceArn:      &s.instanceARN,
+		AccountId:        &accountID,
+		PermissionSetArn: &permissionSetARN,
+	})
+
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page.AccountAssignments {
+			principalType := KindUser
+			if a.PrincipalType == ssoadmintypes.PrincipalTypeGroup {
+				principalType = KindGroup
+			}
+			assignments = append(assignments, PrincipalAssignment{
+				AccountID:        accountID,
+				PermissionSetARN: permissionSetARN,
+				PrincipalType:    principalType,
+				PrincipalID:      value(a.PrincipalId),
+			})
+		}
+	}
+
+	return assignments, nil
+}
+
+const (
+	scoreExact       = 1000
+	scorePrefix      = 800
+	scoreSubstring   = 600
+	scoreSubsequence = 100
+)
+
+func bestScore(needle string, fields ...string) int {
+	best := 0
+	for _, field := range fields {
+		if score := fuzzyScore(needle, strings.ToLower(field)); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func fuzzyScore(needle, haystack string) int {
+	if needle == "" || haystack == "" {
+		return 0
+	}
+	if needle == haystack {
+		return scoreExact
+	}
+	if strings.HasPrefix(haystack, needle) {
+		return scorePrefix - min(len(haystack), 100)
+	}
+	if idx := strings.Index(haystack, needle); idx >= 0 {
+		return scoreSubstring - min(idx, 100)
+	}
+
+	pos, gaps := 0, 0
+	for _, r := range needle {
+		idx := strings.IndexRune(haystack[pos:], r)
+		if idx < 0 {
+			return 0
+		}
+		gaps += idx
+		pos += idx + len(string(r))
+	}
+	return max(1, scoreSubsequence-gaps)
+}
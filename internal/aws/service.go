@@ -1,27 +1,40 @@
 package aws
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
+	"aws-groups-manager/internal/audit"
+	"aws-groups-manager/internal/profiles"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/account"
 	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore/document"
 	identitytypes "github.com/aws/aws-sdk-go-v2/service/identitystore/types"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
 	ssoadmintypes "github.com/aws/aws-sdk-go-v2/service/ssoadmin/types"
+	"github.com/aws/smithy-go/middleware"
 )
 
+const userPageSize = 100
+
 type Instance struct {
 	ARN            string
 	IdentityStore  string
 	DisplayName    string
 	IdentitySource string
+	Region         string
 }
 
 type Group struct {
@@ -55,11 +68,25 @@ type PermissionSet struct {
 	Name string
 }
 
+type UserSearch struct {
+	needle  string
+	pager   *identitystore.ListUsersPaginator
+	seen    map[string]bool
+	scanned int
+}
+
+type UserMembership struct {
+	MembershipID string
+	GroupID      string
+	GroupName    string
+}
+
 type Assignment struct {
 	AccountID         string
 	AccountName       string
 	PermissionSetARN  string
 	PermissionSetName string
+	InheritedFrom     string
 }
 
 type Service struct {
@@ -72,43 +99,56 @@ type Service struct {
 	identityClient *identitystore.Client
 	ssoAdminClient *ssoadmin.Client
 	orgClient      *organizations.Client
+	accountClient  *account.Client
+
+	auditLog *audit.Log
+	options  Options
+
+	awsCfg  awssdk.Config
+	regions []string
+
+	sso         bool
+	loginPrompt func(DeviceAuthorization)
+	mfaPrompt   func(serial string) (string, error)
+	loginMu     sync.Mutex
+	loginGen    int
+	loginErr    error
+}
+
+type Options struct {
+	Concurrency    int
+	RequestTimeout time.Duration
+	PollTimeout    time.Duration
+	AssumeRoleARN  string
+	ExternalID     string
+	MFASerial      string
 }
 
 func NewService(profile, region string) *Service {
 	return &Service{
 		profile: profile,
 		region:  region,
+		options: Options{Concurrency: 1},
 	}
 }
 
 func (s *Service) EnsureSession(ctx context.Context) ([]Instance, error) {
-	if err := s.loadClients(ctx); err != nil {
-		return nil, err
+	instances, err := s.ensureSession(ctx)
+	if s.region != AllRegions {
+		return instances, err
 	}
-
-	instances, err := s.listInstances(ctx)
-	if err == nil {
-		return instances, nil
-	}
-
-	if !isSSOAuthError(err) {
+	if err != nil && (s.awsCfg.Credentials == nil || isAuthExpired(err)) {
 		return nil, err
 	}
+	return s.discoverInstances(ctx)
+}
 
-	if loginErr := s.loginSSO(ctx); loginErr != nil {
-		return nil, fmt.Errorf("aws sso login failed: %w", loginErr)
-	}
-
+func (s *Service) ensureSession(ctx context.Context) ([]Instance, error) {
 	if err := s.loadClients(ctx); err != nil {
 		return nil, err
 	}
 
-	instances, err = s.listInstances(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	return instances, nil
+	return s.listInstances(ctx)
 }
 
 func (s *Service) SetInstance(instanceARN, identityStoreID string) {
@@ -116,6 +156,29 @@ func (s *Service) SetInstance(instanceARN, identityStoreID string) {
 	s.identityStoreID = identityStoreID
 }
 
+func (s *Service) SetAuditLog(log *audit.Log) {
+	s.auditLog = log
+}
+
+func (s *Service) SetOptions(options Options) {
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	s.options = options
+}
+
+func (s *Service) Profile() string {
+	return s.profile
+}
+
+func (s *Service) Region() string {
+	return s.region
+}
+
+func (s *Service) InstanceARN() string {
+	return s.instanceARN
+}
+
 func (s *Service) ListGroups(ctx context.Context) ([]Group, error) {
 	groups := make([]Group, 0, 32)
 	pager := identitystore.NewListGroupsPaginator(s.identityClient, &identitystore.ListGroupsInput{
@@ -140,20 +203,34 @@ func (s *Service) ListGroups(ctx context.Context) ([]Group, error) {
 	return groups, nil
 }
 
-func (s *Service) CreateGroup(ctx context.Context, displayName string) error {
-	_, err := s.identityClient.CreateGroup(ctx, &identitystore.CreateGroupInput{
+func (s *Service) CreateGroup(ctx context.Context, displayName string) (string, error) {
+	resp, err := s.identityClient.CreateGroup(ctx, &identitystore.CreateGroupInput{
 		IdentityStoreId: &s.identityStoreID,
 		DisplayName:     &displayName,
 	})
-	return err
+
+	targets := audit.Targets{GroupName: displayName}
+	var metadata middleware.Metadata
+	if err == nil {
+		targets.GroupID = value(resp.GroupId)
+		metadata = resp.ResultMetadata
+	}
+
+	return targets.GroupID, s.recordMutation("CreateGroup", targets, requestID(metadata, err), err)
 }
 
 func (s *Service) DeleteGroup(ctx context.Context, groupID string) error {
-	_, err := s.identityClient.DeleteGroup(ctx, &identitystore.DeleteGroupInput{
+	resp, err := s.identityClient.DeleteGroup(ctx, &identitystore.DeleteGroupInput{
 		IdentityStoreId: &s.identityStoreID,
 		GroupId:         &groupID,
 	})
-	return err
+
+	var metadata middleware.Metadata
+	if err == nil {
+		metadata = resp.ResultMetadata
+	}
+
+	return s.recordMutation("DeleteGroup", audit.Targets{GroupID: groupID}, requestID(metadata, err), err)
 }
 
 func (s *Service) GroupMembershipCount(ctx context.Context, groupID string) (int, error) {
@@ -222,55 +299,243 @@ func (s *Service) ListGroupUsers(ctx context.Context, groupID string) ([]GroupUs
 }
 
 func (s *Service) ListUsers(ctx context.Context) ([]User, error) {
+	users, _, err := s.ListUsersLimit(ctx, 0)
+	return users, err
+}
+
+func (s *Service) ListUsersLimit(ctx context.Context, limit int) ([]User, bool, error) {
 	users := make([]User, 0, 256)
+	pageSize := int32(userPageSize)
 	pager := identitystore.NewListUsersPaginator(s.identityClient, &identitystore.ListUsersInput{
 		IdentityStoreId: &s.identityStoreID,
+		MaxResults:      &pageSize,
 	})
 
 	for pager.HasMorePages() {
+		if limit > 0 && len(users) >= limit {
+			return users, false, nil
+		}
+
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		for _, u := range page.Users {
-			user := User{
-				ID:          value(u.UserId),
-				DisplayName: value(u.DisplayName),
-				UserName:    value(u.UserName),
-				Email:       firstUserEmail(u.Emails),
+			users = append(users, userFromIdentity(u))
+		}
+	}
+
+	return users, true, nil
+}
+
+func (s *Service) SearchUsers(ctx context.Context, query string) (*UserSearch, []User, error) {
+	query = strings.TrimSpace(query)
+	pageSize := int32(userPageSize)
+	search := &UserSearch{
+		needle: strings.ToLower(query),
+		pager: identitystore.NewListUsersPaginator(s.identityClient, &identitystore.ListUsersInput{
+			IdentityStoreId: &s.identityStoreID,
+			MaxResults:      &pageSize,
+		}),
+		seen: make(map[string]bool),
+	}
+
+	exact, err := s.FindUser(ctx, query)
+	switch {
+	case err == nil:
+		search.seen[exact.ID] = true
+		return search, []User{exact}, nil
+	case !errors.Is(err, ErrUserNotFound):
+		return nil, nil, err
+	}
+	return search, nil, nil
+}
+
+func (u *UserSearch) Next(ctx context.Context) ([]User, error) {
+	page, err := u.pager.NextPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0)
+	for _, identityUser := range page.Users {
+		u.scanned++
+		user := userFromIdentity(identityUser)
+		if u.seen[user.ID] || !userMatches(user, u.needle) {
+			continue
+		}
+		u.seen[user.ID] = true
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (u *UserSearch) Done() bool {
+	return !u.pager.HasMorePages()
+}
+
+func (u *UserSearch) Scanned() int {
+	return u.scanned
+}
+
+func (s *Service) GetUser(ctx context.Context, userID string) (User, error) {
+	resp, err := s.identityClient.DescribeUser(ctx, &identitystore.DescribeUserInput{
+		IdentityStoreId: &s.identityStoreID,
+		UserId:          &userID,
+	})
+	if err != nil {
+		var notFound *identitytypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+
+	user := User{
+		ID:          value(resp.UserId),
+		DisplayName: value(resp.DisplayName),
+		UserName:    value(resp.UserName),
+		Email:       firstUserEmail(resp.Emails),
+	}
+	if user.DisplayName == "" {
+		user.DisplayName = cmp.Or(user.UserName, user.ID)
+	}
+	return user, nil
+}
+
+func (s *Service) FindUser(ctx context.Context, query string) (User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return User{}, ErrUserNotFound
+	}
+
+	for _, path := range []string{"userName", "emails.value"} {
+		userID, err := s.lookupUserID(ctx, path, query)
+		if err == nil {
+			return s.GetUser(ctx, userID)
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return User{}, err
+		}
+	}
+
+	user, err := s.GetUser(ctx, query)
+	if err != nil {
+		var validation *identitytypes.ValidationException
+		if errors.As(err, &validation) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (s *Service) lookupUserID(ctx context.Context, attributePath, attributeValue string) (string, error) {
+	resp, err := s.identityClient.GetUserId(ctx, &identitystore.GetUserIdInput{
+		IdentityStoreId: &s.identityStoreID,
+		AlternateIdentifier: &identitytypes.AlternateIdentifierMemberUniqueAttribute{
+			Value: identitytypes.UniqueAttribute{
+				AttributePath:  &attributePath,
+				AttributeValue: document.NewLazyDocument(attributeValue),
+			},
+		},
+	})
+	if err != nil {
+		var notFound *identitytypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	return value(resp.UserId), nil
+}
+
+func (s *Service) ListUserMemberships(ctx context.Context, userID string, groupNames map[string]string) ([]UserMembership, error) {
+	memberships := make([]UserMembership, 0, 16)
+	pager := identitystore.NewListGroupMembershipsForMemberPaginator(s.identityClient, &identitystore.ListGroupMembershipsForMemberInput{
+		IdentityStoreId: &s.identityStoreID,
+		MemberId:        &identitytypes.MemberIdMemberUserId{Value: userID},
+	})
+
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.GroupMemberships {
+			membership := UserMembership{
+				MembershipID: value(m.MembershipId),
+				GroupID:      value(m.GroupId),
+				GroupName:    groupNames[value(m.GroupId)],
 			}
-			if user.DisplayName == "" {
-				if user.UserName != "" {
-					user.DisplayName = user.UserName
-				} else {
-					user.DisplayName = user.ID
+
+			if membership.GroupName == "" {
+				group, err := s.identityClient.DescribeGroup(ctx, &identitystore.DescribeGroupInput{
+					IdentityStoreId: &s.identityStoreID,
+					GroupId:         m.GroupId,
+				})
+				if err == nil {
+					membership.GroupName = value(group.DisplayName)
 				}
 			}
-			users = append(users, user)
+			if membership.GroupName == "" {
+				membership.GroupName = membership.GroupID
+			}
+
+			memberships = append(memberships, membership)
 		}
 	}
 
-	return users, nil
+	return memberships, nil
+}
+
+func (s *Service) ListUserAssignments(ctx context.Context, userID string) ([]Assignment, error) {
+	return s.listPrincipalAssignments(ctx, ssoadmintypes.PrincipalTypeUser, userID)
+}
+
+func (s *Service) ListUserAccess(ctx context.Context, userID string) (direct, inherited []Assignment, err error) {
+	all, err := s.principalAssignments(ctx, ssoadmintypes.PrincipalTypeUser, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	direct, inherited = splitInherited(all)
+	return direct, inherited, nil
 }
 
-func (s *Service) AddUserToGroup(ctx context.Context, groupID, userID string) error {
-	_, err := s.identityClient.CreateGroupMembership(ctx, &identitystore.CreateGroupMembershipInput{
+func (s *Service) AddUserToGroup(ctx context.Context, groupID, userID string) (string, error) {
+	resp, err := s.identityClient.CreateGroupMembership(ctx, &identitystore.CreateGroupMembershipInput{
 		IdentityStoreId: &s.identityStoreID,
 		GroupId:         &groupID,
 		MemberId: &identitytypes.MemberIdMemberUserId{
 			Value: userID,
 		},
 	})
-	return err
+
+	targets := audit.Targets{GroupID: groupID, UserID: userID}
+	var metadata middleware.Metadata
+	if err == nil {
+		targets.MembershipID = value(resp.MembershipId)
+		metadata = resp.ResultMetadata
+	}
+
+	return targets.MembershipID, s.recordMutation("AddUserToGroup", targets, requestID(metadata, err), err)
 }
 
-func (s *Service) RemoveUserFromGroup(ctx context.Context, membershipID string) error {
-	_, err := s.identityClient.DeleteGroupMembership(ctx, &identitystore.DeleteGroupMembershipInput{
+func (s *Service) RemoveUserFromGroup(ctx context.Context, groupID, userID, membershipID string) error {
+	resp, err := s.identityClient.DeleteGroupMembership(ctx, &identitystore.DeleteGroupMembershipInput{
 		IdentityStoreId: &s.identityStoreID,
 		MembershipId:    &membershipID,
 	})
-	return err
+
+	var metadata middleware.Metadata
+	if err == nil {
+		metadata = resp.ResultMetadata
+	}
+
+	targets := audit.Targets{GroupID: groupID, UserID: userID, MembershipID: membershipID}
+	return s.recordMutation("RemoveUserFromGroup", targets, requestID(metadata, err), err)
 }
 
 func (s *Service) ListAccounts(ctx context.Context) ([]Account, error) {
@@ -340,39 +605,135 @@ func (s *Service) ListPermissionSets(ctx context.Context) ([]PermissionSet, erro
 }
 
 func (s *Service) DiscoverAssignments(ctx context.Context, groupID string, accounts []Account, permissionSets []PermissionSet) ([]Assignment, error) {
-	assignments := make([]Assignment, 0, 256)
+	found := make([][]Assignment, len(accounts)*len(permissionSets))
 
-	for _, account := range accounts {
-		for _, ps := range permissionSets {
-			select {
-			case <-ctx.Done():
-				return assignments, ctx.Err()
-			default:
-			}
+	err := s.parallel(ctx, len(found), func(ctx context.Context, i int) error {
+		account := accounts[i/len(permissionSets)]
+		ps := permissionSets[i%len(permissionSets)]
 
-			pager := ssoadmin.NewListAccountAssignmentsPaginator(s.ssoAdminClient, &ssoadmin.ListAccountAssignmentsInput{
-				InstanceArn:      &s.instanceARN,
-				AccountId:        &account.ID,
-				PermissionSetArn: &ps.ARN,
-			})
+		pager := ssoadmin.NewListAccountAssignmentsPaginator(s.ssoAdminClient, &ssoadmin.ListAccountAssignmentsInput{
+			InstanceArn:      &s.instanceARN,
+			AccountId:        &account.ID,
+			PermissionSetArn: &ps.ARN,
+		})
+
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return err
+			}
 
-			for pager.HasMorePages() {
-				page, err := pager.NextPage(ctx)
-				if err != nil {
-					return assignments, err
+			for _, a := range page.AccountAssignments {
+				if a.PrincipalType == ssoadmintypes.PrincipalTypeGroup && value(a.PrincipalId) == groupID {
+					found[i] = append(found[i], Assignment{
+						AccountID:         account.ID,
+						AccountName:       account.Name,
+						PermissionSetARN:  ps.ARN,
+						PermissionSetName: ps.Name,
+					})
 				}
+			}
+		}
+		return nil
+	})
+
+	assignments := make([]Assignment, 0, 256)
+	for _, batch := range found {
+		assignments = append(assignments, batch...)
+	}
+	return assignments, err
+}
 
-				for _, a := range page.AccountAssignments {
-					if a.PrincipalType == ssoadmintypes.PrincipalTypeGroup && value(a.PrincipalId) == groupID {
-						assignments = append(assignments, Assignment{
-							AccountID:         account.ID,
-							AccountName:       account.Name,
-							PermissionSetARN:  ps.ARN,
-							PermissionSetName: ps.Name,
-						})
-					}
+func (s *Service) parallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+	for w := 0; w < min(s.options.Concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
 				}
 			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (s *Service) ListGroupAssignments(ctx context.Context, groupID string) ([]Assignment, error) {
+	return s.listPrincipalAssignments(ctx, ssoadmintypes.PrincipalTypeGroup, groupID)
+}
+
+func (s *Service) listPrincipalAssignments(ctx context.Context, principalType ssoadmintypes.PrincipalType, principalID string) ([]Assignment, error) {
+	all, err := s.principalAssignments(ctx, principalType, principalID)
+	if err != nil {
+		return nil, err
+	}
+	direct, _ := splitInherited(all)
+	return direct, nil
+}
+
+func splitInherited(all []Assignment) (direct, inherited []Assignment) {
+	direct = make([]Assignment, 0, len(all))
+	for _, a := range all {
+		if a.InheritedFrom != "" {
+			inherited = append(inherited, a)
+			continue
+		}
+		direct = append(direct, a)
+	}
+	return direct, inherited
+}
+
+func (s *Service) principalAssignments(ctx context.Context, principalType ssoadmintypes.PrincipalType, principalID string) ([]Assignment, error) {
+	assignments := make([]Assignment, 0, 32)
+	pager := ssoadmin.NewListAccountAssignmentsForPrincipalPaginator(s.ssoAdminClient, &ssoadmin.ListAccountAssignmentsForPrincipalInput{
+		InstanceArn:   &s.instanceARN,
+		PrincipalId:   &principalID,
+		PrincipalType: principalType,
+	})
+
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range page.AccountAssignments {
+			assignment := Assignment{
+				AccountID:        value(a.AccountId),
+				PermissionSetARN: value(a.PermissionSetArn),
+			}
+			if a.PrincipalType != principalType || value(a.PrincipalId) != principalID {
+				assignment.InheritedFrom = value(a.PrincipalId)
+			}
+			assignments = append(assignments, assignment)
 		}
 	}
 
@@ -380,6 +741,8 @@ func (s *Service) DiscoverAssignments(ctx context.Context, groupID string, accou
 }
 
 func (s *Service) CreateAssignment(ctx context.Context, groupID, accountID, permissionSetARN string) error {
+	targets := audit.Targets{GroupID: groupID, AccountID: accountID, PermissionSetARN: permissionSetARN}
+
 	resp, err := s.ssoAdminClient.CreateAccountAssignment(ctx, &ssoadmin.CreateAccountAssignmentInput{
 		InstanceArn:      &s.instanceARN,
 		PermissionSetArn: &permissionSetARN,
@@ -389,56 +752,123 @@ func (s *Service) CreateAssignment(ctx context.Context, groupID, accountID, perm
 		TargetId:         &accountID,
 	})
 	if err != nil {
-		return err
+		return s.recordMutation("CreateAssignment", targets, requestID(middleware.Metadata{}, err), err)
 	}
 
+	awsRequestID := requestID(resp.ResultMetadata, nil)
 	if resp.AccountAssignmentCreationStatus == nil || resp.AccountAssignmentCreationStatus.RequestId == nil {
-		return fmt.Errorf("missing assignment creation request id")
+		err := fmt.Errorf("missing assignment creation request id")
+		return s.recordMutation("CreateAssignment", targets, awsRequestID, err)
 	}
 
-	requestID := *resp.AccountAssignmentCreationStatus.RequestId
-	return s.pollCreation(ctx, requestID)
+	err = s.pollCreation(ctx, *resp.AccountAssignmentCreationStatus.RequestId)
+	return s.recordMutation("CreateAssignment", targets, awsRequestID, err)
 }
 
 func (s *Service) DeleteAssignment(ctx context.Context, groupID, accountID, permissionSetARN string) error {
+	targets := audit.Targets{GroupID: groupID, AccountID: accountID, PermissionSetARN: permissionSetARN}
+	return s.deleteAssignment(ctx, ssoadmintypes.PrincipalTypeGroup, groupID, accountID, permissionSetARN, targets)
+}
+
+func (s *Service) DeleteUserAssignment(ctx context.Context, userID, accountID, permissionSetARN string) error {
+	targets := audit.Targets{UserID: userID, AccountID: accountID, PermissionSetARN: permissionSetARN}
+	return s.deleteAssignment(ctx, ssoadmintypes.PrincipalTypeUser, userID, accountID, permissionSetARN, targets)
+}
+
+func (s *Service) deleteAssignment(ctx context.Context, principalType ssoadmintypes.PrincipalType, principalID, accountID, permissionSetARN string, targets audit.Targets) error {
 	resp, err := s.ssoAdminClient.DeleteAccountAssignment(ctx, &ssoadmin.DeleteAccountAssignmentInput{
 		InstanceArn:      &s.instanceARN,
 		PermissionSetArn: &permissionSetARN,
-		PrincipalType:    ssoadmintypes.PrincipalTypeGroup,
-		PrincipalId:      &groupID,
+		PrincipalType:    principalType,
+		PrincipalId:      &principalID,
 		TargetType:       ssoadmintypes.TargetTypeAwsAccount,
 		TargetId:         &accountID,
 	})
 	if err != nil {
-		return err
+		return s.recordMutation("DeleteAssignment", targets, requestID(middleware.Metadata{}, err), err)
 	}
 
+	awsRequestID := requestID(resp.ResultMetadata, nil)
 	if resp.AccountAssignmentDeletionStatus == nil || resp.AccountAssignmentDeletionStatus.RequestId == nil {
-		return fmt.Errorf("missing assignment deletion request id")
+		err := fmt.Errorf("missing assignment deletion request id")
+		return s.recordMutation("DeleteAssignment", targets, awsRequestID, err)
 	}
 
-	requestID := *resp.AccountAssignmentDeletionStatus.RequestId
-	return s.pollDeletion(ctx, requestID)
+	err = s.pollDeletion(ctx, *resp.AccountAssignmentDeletionStatus.RequestId)
+	return s.recordMutation("DeleteAssignment", targets, awsRequestID, err)
 }
 
 func (s *Service) loadClients(ctx context.Context) error {
-	cfg, err := awsconfig.LoadDefaultConfig(ctx,
-		awsconfig.WithRegion(s.region),
-		awsconfig.WithSharedConfigProfile(s.profile),
-	)
+	region := s.region
+	if region == AllRegions {
+		region = discoveryRegion
+	}
+	loadOptions := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(region),
+		awsconfig.WithAssumeRoleCredentialOptions(s.profileMFA),
+	}
+	if s.profile != profiles.NoProfile {
+		loadOptions = append(loadOptions, awsconfig.WithSharedConfigProfile(s.profile))
+	}
+	if s.options.RequestTimeout > 0 {
+		loadOptions = append(loadOptions, awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(s.options.RequestTimeout)))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
 		return err
 	}
+	if s.options.AssumeRoleARN != "" {
+		cfg.Credentials = s.assumeRole(cfg)
+	}
+	if _, s.sso, err = ssoProfile(s.profile); err != nil {
+		return err
+	}
 
+	cfg.APIOptions = append(cfg.APIOptions, s.addReauthMiddleware)
+	s.awsCfg = cfg
 	s.identityClient = identitystore.NewFromConfig(cfg)
 	s.ssoAdminClient = ssoadmin.NewFromConfig(cfg)
 	s.orgClient = organizations.NewFromConfig(cfg)
+	s.accountClient = account.NewFromConfig(cfg, func(o *account.Options) {
+		o.Region = discoveryRegion
+	})
 
 	return nil
 }
 
+func SelectInstance(instances []Instance, instanceARN, identityStoreID string) (Instance, error) {
+	if instanceARN != "" || identityStoreID != "" {
+		for _, inst := range instances {
+			if (instanceARN == "" || inst.ARN == instanceARN) && (identityStoreID == "" || inst.IdentityStore == identityStoreID) {
+				return inst, nil
+			}
+		}
+		wanted := instanceARN
+		switch {
+		case instanceARN == "":
+			wanted = "with identity store " + identityStoreID
+		case identityStoreID != "":
+			wanted += " with identity store " + identityStoreID
+		}
+		return Instance{}, fmt.Errorf("instance %s not found (%d instances available)", wanted, len(instances))
+	}
+
+	switch len(instances) {
+	case 0:
+		return Instance{}, fmt.Errorf("no Identity Center instances found")
+	case 1:
+		return instances[0], nil
+	default:
+		return Instance{}, fmt.Errorf("%w: found %d", ErrMultipleInstances, len(instances))
+	}
+}
+
 func (s *Service) listInstances(ctx context.Context) ([]Instance, error) {
-	pager := ssoadmin.NewListInstancesPaginator(s.ssoAdminClient, &ssoadmin.ListInstancesInput{})
+	return listInstancesWith(ctx, s.ssoAdminClient, s.ssoAdminClient.Options().Region)
+}
+
+func listInstancesWith(ctx context.Context, client *ssoadmin.Client, region string) ([]Instance, error) {
+	pager := ssoadmin.NewListInstancesPaginator(client, &ssoadmin.ListInstancesInput{})
 	instances := make([]Instance, 0, 4)
 
 	for pager.HasMorePages() {
@@ -453,6 +883,7 @@ func (s *Service) listInstances(ctx context.Context) ([]Instance, error) {
 				IdentityStore:  value(i.IdentityStoreId),
 				DisplayName:    instanceDisplayName(i),
 				IdentitySource: value(i.OwnerAccountId),
+				Region:         region,
 			})
 		}
 	}
@@ -460,20 +891,21 @@ func (s *Service) listInstances(ctx context.Context) ([]Instance, error) {
 	return instances, nil
 }
 
-func (s *Service) loginSSO(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "aws", "sso", "login", "--profile", s.profile)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+func (s *Service) pollContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.options.PollTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	return nil
+	return context.WithTimeoutCause(ctx, s.options.PollTimeout, fmt.Errorf("still in progress after %s", s.options.PollTimeout))
 }
 
 func (s *Service) pollCreation(ctx context.Context, requestID string) error {
+	ctx, cancel := s.pollContext(ctx)
+	defer cancel()
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return context.Cause(ctx)
 		case <-time.After(2 * time.Second):
 		}
 
@@ -504,10 +936,13 @@ func (s *Service) pollCreation(ctx context.Context, requestID string) error {
 }
 
 func (s *Service) pollDeletion(ctx context.Context, requestID string) error {
+	ctx, cancel := s.pollContext(ctx)
+	defer cancel()
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return context.Cause(ctx)
 		case <-time.After(2 * time.Second):
 		}
 
@@ -537,27 +972,43 @@ func (s *Service) pollDeletion(ctx context.Context, requestID string) error {
 	}
 }
 
-func isSSOAuthError(err error) bool {
-	if err == nil {
-		return false
+func (s *Service) recordMutation(action string, targets audit.Targets, awsRequestID string, err error) error {
+	if s.auditLog == nil {
+		return err
 	}
 
-	msg := strings.ToLower(err.Error())
-	signals := []string{
-		"sso session",
-		"token has expired",
-		"expired token",
-		"unauthorized",
-		"invalid_grant",
+	rec := audit.Record{
+		Profile:     s.profile,
+		Region:      s.region,
+		InstanceARN: s.instanceARN,
+		Action:      action,
+		Targets:     targets,
+		Outcome:     audit.OutcomeSuccess,
+		RequestID:   awsRequestID,
+	}
+	if err != nil {
+		rec.Outcome = audit.OutcomeFailure
+		rec.Error = err.Error()
 	}
 
-	for _, signal := range signals {
-		if strings.Contains(msg, signal) {
-			return true
+	if logErr := s.auditLog.Append(rec); logErr != nil {
+		return errors.Join(err, fmt.Errorf("write audit record: %w", logErr))
+	}
+
+	return err
+}
+
+func requestID(metadata middleware.Metadata, err error) string {
+	if err != nil {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) {
+			return respErr.ServiceRequestID()
 		}
+		return ""
 	}
 
-	return false
+	id, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	return id
 }
 
 func instanceDisplayName(instance ssoadmintypes.InstanceMetadata) string {
@@ -568,6 +1019,28 @@ func instanceDisplayName(instance ssoadmintypes.InstanceMetadata) string {
 	return parts[len(parts)-1]
 }
 
+func userFromIdentity(u identitytypes.User) User {
+	user := User{
+		ID:          value(u.UserId),
+		DisplayName: value(u.DisplayName),
+		UserName:    value(u.UserName),
+		Email:       firstUserEmail(u.Emails),
+	}
+	if user.DisplayName == "" {
+		user.DisplayName = cmp.Or(user.UserName, user.ID)
+	}
+	return user
+}
+
+func userMatches(user User, needle string) bool {
+	for _, field := range []string{user.UserName, user.DisplayName, user.Email} {
+		if strings.Contains(strings.ToLower(field), needle) {
+			return true
+		}
+	}
+	return false
+}
+
 func firstUserEmail(values []identitytypes.Email) string {
 	if len(values) == 0 {
 		return ""
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelectInstance(t *testing.T) {
+	prod := Instance{ARN: "arn:aws:sso:::instance/ssoins-prod", IdentityStore: "d-prod"}
+	dev := Instance{ARN: "arn:aws:sso:::instance/ssoins-dev", IdentityStore: "d-dev"}
+
+	tests := []struct {
+		name          string
+		instances     []Instance
+		instanceARN   string
+		identityStore string
+		want          Instance
+		wantErr       error
+	}{
+		{name: "single instance", instances: []Instance{prod}, want: prod},
+		{name: "by ARN", instances: []Instance{prod, dev}, instanceARN: dev.ARN, want: dev},
+		{name: "by identity store", instances: []Instance{prod, dev}, identityStore: "d-prod", want: prod},
+		{name: "by ARN and identity store", instances: []Instance{prod, dev}, instanceARN: dev.ARN, identityStore: "d-dev", want: dev},
+		{name: "ARN and identity store disagree", instances: []Instance{prod, dev}, instanceARN: dev.ARN, identityStore: "d-prod"},
+		{name: "unknown ARN", instances: []Instance{prod}, instanceARN: dev.ARN},
+		{name: "no instances", instances: nil},
+		{name: "several instances", instances: []Instance{prod, dev}, wantErr: ErrMultipleInstances},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectInstance(tt.instances, tt.instanceARN, tt.identityStore)
+			if tt.want == (Instance{}) {
+				if err == nil {
+					t.Fatalf("SelectInstance = %+v, want an error", got)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SelectInstance error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("SelectInstance = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+
+	ResumeOff     = "off"
+	ResumeSession = "session"
+	ResumeGroup   = "group"
+
+	DefaultConcurrency    = 4
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultPollTimeout    = 5 * time.Minute
+)
+
+const envPrefix = "AGM_"
+
+type Timeouts struct {
+	Request time.Duration `yaml:"request"`
+	Poll    time.Duration `yaml:"poll"`
+}
+
+type Config struct {
+	Context         string              `yaml:"context"`
+	Profile         string              `yaml:"profile"`
+	Region          string              `yaml:"region"`
+	InstanceARN     string              `yaml:"instance_arn"`
+	IdentityStoreID string              `yaml:"identity_store_id"`
+	AssumeRoleARN   string              `yaml:"assume_role_arn"`
+	ExternalID      string              `yaml:"external_id"`
+	MFASerial       string              `yaml:"mfa_serial"`
+	Theme           string              `yaml:"theme"`
+	Keymap          string              `yaml:"keymap"`
+	KeyBindings     map[string][]string `yaml:"key_bindings"`
+	Concurrency     int                 `yaml:"concurrency"`
+	Timeouts        Timeouts            `yaml:"timeouts"`
+	OutputFormat    string              `yaml:"output_format"`
+	Resume          string              `yaml:"resume"`
+
+	Warnings []string `yaml:"-"`
+}
+
+func Defaults() Config {
+	return Config{
+		Theme:        "dark",
+		Concurrency:  DefaultConcurrency,
+		Timeouts:     Timeouts{Request: DefaultRequestTimeout, Poll: DefaultPollTimeout},
+		OutputFormat: OutputText,
+		Resume:       ResumeSession,
+	}
+}
+
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "config.yaml"), nil
+}
+
+func Load(path string, contexts *Contexts, contextName string) (Config, error) {
+	cfg := Defaults()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return Config{}, err
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "keys.json")); err == nil {
+		cfg.Warnings = append(cfg.Warnings, "keys.json is no longer read; move its preset to keymap and its bindings to key_bindings in "+path)
+	}
+
+	if err := cfg.applyContext(os.LookupEnv, contexts, contextName); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) applyContext(lookup func(string) (string, bool), contexts *Contexts, name string) error {
+	explicit := name != ""
+	if name == "" {
+		name, _ = lookup(envPrefix + "CONTEXT")
+	}
+	if name == "" {
+		name = c.Context
+	}
+	if name == "" {
+		if _, ok := contexts.Contexts[contexts.Current]; !ok {
+			return nil
+		}
+		name = contexts.Current
+	}
+
+	ctx, err := contexts.Get(name)
+	if err != nil {
+		if explicit || !errors.Is(err, ErrContextNotFound) {
+			return err
+		}
+		c.Context = ""
+		c.Warnings = append(c.Warnings, fmt.Sprintf("context %q not found; ignoring it", name))
+		return nil
+	}
+	c.Context = name
+	c.Profile = ctx.Profile
+	c.Region = ctx.Region
+	c.InstanceARN = ctx.InstanceARN
+	c.IdentityStoreID = ""
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	strs := map[string]*string{
+		"PROFILE":           &c.Profile,
+		"REGION":            &c.Region,
+		"INSTANCE_ARN":      &c.InstanceARN,
+		"IDENTITY_STORE_ID": &c.IdentityStoreID,
+		"ASSUME_ROLE_ARN":   &c.AssumeRoleARN,
+		"EXTERNAL_ID":       &c.ExternalID,
+		"MFA_SERIAL":        &c.MFASerial,
+		"THEME":             &c.Theme,
+		"KEYMAP":            &c.Keymap,
+		"OUTPUT_FORMAT":     &c.OutputFormat,
+		"RESUME":            &c.Resume,
+	}
+	for name, dst := range strs {
+		if v, ok := lookup(envPrefix + name); ok && v != "" {
+			*dst = v
+		}
+	}
+
+	if v, ok := lookup(envPrefix + "CONCURRENCY"); ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%sCONCURRENCY: %w", envPrefix, err)
+		}
+		c.Concurrency = n
+	}
+
+	durations := map[string]*time.Duration{
+		"REQUEST_TIMEOUT": &c.Timeouts.Request,
+		"POLL_TIMEOUT":    &c.Timeouts.Poll,
+	}
+	for name, dst := range durations {
+		v, ok := lookup(envPrefix + name)
+		if !ok || v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", envPrefix, name, err)
+		}
+		*dst = d
+	}
+
+	return nil
+}
+
+func (c Config) Validate() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", c.Concurrency)
+	}
+	if c.Timeouts.Request < 0 || c.Timeouts.Poll < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+	if c.AssumeRoleARN == "" && (c.ExternalID != "" || c.MFASerial != "") {
+		return fmt.Errorf("external_id and mfa_serial need assume_role_arn")
+	}
+	if c.OutputFormat != OutputText && c.OutputFormat != OutputJSON {
+		return fmt.Errorf("unknown output format %q (available: %s, %s)", c.OutputFormat, OutputText, OutputJSON)
+	}
+	switch c.Resume {
+	case ResumeOff, ResumeSession, ResumeGroup:
+	default:
+		return fmt.Errorf("unknown resume mode %q (available: %s, %s, %s)", c.Resume, ResumeOff, ResumeSession, ResumeGroup)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testContexts() *Contexts {
+	return &Contexts{
+		Current: "staging",
+		Contexts: map[string]Context{
+			"prod":    {Profile: "org-prod", Region: "eu-west-1", InstanceARN: "arn:aws:sso:::instance/ssoins-prod"},
+			"staging": {Profile: "org-staging", Region: "us-east-1"},
+		},
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, "profile: file-profile\nregion: eu-central-1\nidentity_store_id: d-file\nconcurrency: 8\n")
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		context     string
+		wantContext string
+		wantProfile string
+		wantRegion  string
+		wantStore   string
+	}{
+		{
+			name:        "current context replaces file values",
+			wantContext: "staging",
+			wantProfile: "org-staging",
+			wantRegion:  "us-east-1",
+		},
+		{
+			name:        "env context beats current",
+			env:         map[string]string{"AGM_CONTEXT": "prod"},
+			wantContext: "prod",
+			wantProfile: "org-prod",
+			wantRegion:  "eu-west-1",
+		},
+		{
+			name:        "explicit context beats env",
+			env:         map[string]string{"AGM_CONTEXT": "staging"},
+			context:     "prod",
+			wantContext: "prod",
+			wantProfile: "org-prod",
+			wantRegion:  "eu-west-1",
+		},
+		{
+			name:        "env variables override the context",
+			env:         map[string]string{"AGM_PROFILE": "env-profile", "AGM_IDENTITY_STORE_ID": "d-env"},
+			wantContext: "staging",
+			wantProfile: "env-profile",
+			wantRegion:  "us-east-1",
+			wantStore:   "d-env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"AGM_CONTEXT", "AGM_PROFILE", "AGM_REGION", "AGM_IDENTITY_STORE_ID"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg, err := Load(path, testContexts(), tt.context)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Context != tt.wantContext || cfg.Profile != tt.wantProfile || cfg.Region != tt.wantRegion || cfg.IdentityStoreID != tt.wantStore {
+				t.Errorf("got context=%q profile=%q region=%q store=%q, want %q %q %q %q",
+					cfg.Context, cfg.Profile, cfg.Region, cfg.IdentityStoreID,
+					tt.wantContext, tt.wantProfile, tt.wantRegion, tt.wantStore)
+			}
+			if cfg.Concurrency != 8 {
+				t.Errorf("concurrency = %d, want 8 from the file", cfg.Concurrency)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutContexts(t *testing.T) {
+	path := writeConfig(t, "profile: file-profile\nregion: eu-central-1\n")
+	t.Setenv("AGM_CONTEXT", "")
+	t.Setenv("AGM_REGION", "ap-south-1")
+	t.Setenv("AGM_POLL_TIMEOUT", "90s")
+
+	cfg, err := Load(path, &Contexts{Contexts: map[string]Context{}}, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Profile != "file-profile" || cfg.Region != "ap-south-1" {
+		t.Errorf("got profile=%q region=%q", cfg.Profile, cfg.Region)
+	}
+	if cfg.Timeouts.Poll != 90*time.Second || cfg.Timeouts.Request != DefaultRequestTimeout {
+		t.Errorf("got timeouts %+v", cfg.Timeouts)
+	}
+}
+
+func TestLoadMissingContext(t *testing.T) {
+	t.Run("from config file", func(t *testing.T) {
+		path := writeConfig(t, "context: gone\nprofile: file-profile\n")
+		t.Setenv("AGM_CONTEXT", "")
+
+		cfg, err := Load(path, &Contexts{Contexts: map[string]Context{}}, "")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Context != "" || cfg.Profile != "file-profile" || len(cfg.Warnings) != 1 {
+			t.Errorf("got context=%q profile=%q warnings=%q", cfg.Context, cfg.Profile, cfg.Warnings)
+		}
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("AGM_CONTEXT", "gone")
+
+		cfg, err := Load(writeConfig(t, ""), testContexts(), "")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Context != "" || len(cfg.Warnings) != 1 {
+			t.Errorf("got context=%q warnings=%q", cfg.Context, cfg.Warnings)
+		}
+	})
+
+	t.Run("from flag", func(t *testing.T) {
+		t.Setenv("AGM_CONTEXT", "")
+
+		_, err := Load(writeConfig(t, ""), testContexts(), "gone")
+		if !errors.Is(err, ErrContextNotFound) {
+			t.Fatalf("Load error = %v, want ErrContextNotFound", err)
+		}
+	})
+}
+
+func TestLoadRejectsBadEnv(t *testing.T) {
+	t.Setenv("AGM_CONTEXT", "")
+	t.Setenv("AGM_CONCURRENCY", "many")
+
+	if _, err := Load(writeConfig(t, ""), &Contexts{Contexts: map[string]Context{}}, ""); err == nil {
+		t.Fatal("Load accepted AGM_CONCURRENCY=many")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrContextNotFound = errors.New("context not found")
+
+type Context struct {
+	Profile     string `yaml:"profile"`
+	Region      string `yaml:"region"`
+	InstanceARN string `yaml:"instance_arn,omitempty"`
+}
+
+type Contexts struct {
+	Current  string             `yaml:"current,omitempty"`
+	Contexts map[string]Context `yaml:"contexts"`
+
+	path string
+}
+
+func DefaultContextsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "contexts.yaml"), nil
+}
+
+func LoadContexts(path string) (*Contexts, error) {
+	c := &Contexts{Contexts: make(map[string]Context), path: path}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if c.Contexts == nil {
+			c.Contexts = make(map[string]Context)
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Contexts) Get(name string) (Context, error) {
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return Context{}, fmt.Errorf("%w: %q", ErrContextNotFound, name)
+	}
+	return ctx, nil
+}
+
+func (c *Contexts) Add(name string, ctx Context) error {
+	if name == "" {
+		return fmt.Errorf("context name is required")
+	}
+	if ctx.Profile == "" || ctx.Region == "" {
+		return fmt.Errorf("context %q needs a profile and a region", name)
+	}
+	c.Contexts[name] = ctx
+	return c.save()
+}
+
+func (c *Contexts) Use(name string) error {
+	if _, err := c.Get(name); err != nil {
+		return err
+	}
+	c.Current = name
+	return c.save()
+}
+
+func (c *Contexts) Names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Contexts) save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
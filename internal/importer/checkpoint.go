@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type Checkpoint struct {
+	path string
+	done map[int]bool
+
+	Source    string    `json:"source"`
+	Digest    string    `json:"digest"`
+	DoneLines []int     `json:"done_lines"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func DefaultCheckpointPath(digest string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "imports", digest[:16]+".json"), nil
+}
+
+func LoadCheckpoint(path, source, digest string) (*Checkpoint, error) {
+	cp := &Checkpoint{path: path, done: map[int]bool{}, Source: source, Digest: digest}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cp, nil
+		}
+		return nil, err
+	}
+
+	var saved Checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	if saved.Digest != digest {
+		return nil, fmt.Errorf("checkpoint %s belongs to a different CSV file; remove it or use --restart", path)
+	}
+
+	for _, line := range saved.DoneLines {
+		cp.done[line] = true
+	}
+	cp.DoneLines = saved.DoneLines
+	return cp, nil
+}
+
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+func (c *Checkpoint) Len() int {
+	return len(c.done)
+}
+
+func (c *Checkpoint) IsDone(line int) bool {
+	return c.done[line]
+}
+
+func (c *Checkpoint) MarkDone(line int) error {
+	c.done[line] = true
+	c.DoneLines = append(c.DoneLines, line)
+	sort.Ints(c.DoneLines)
+	c.UpdatedAt = time.Now().UTC()
+	return c.save()
+}
+
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (c *Checkpoint) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "imports", "abc.json")
+	digest := Digest([]byte("Admins,alice\nAdmins,bob\n"))
+
+	cp, err := LoadCheckpoint(path, "users.csv", digest)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if cp.Len() != 0 {
+		t.Fatalf("new checkpoint has %d done lines", cp.Len())
+	}
+	for _, line := range []int{4, 1} {
+		if err := cp.MarkDone(line); err != nil {
+			t.Fatalf("MarkDone(%d): %v", line, err)
+		}
+	}
+
+	resumed, err := LoadCheckpoint(path, "users.csv", digest)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint after MarkDone: %v", err)
+	}
+	if !resumed.IsDone(1) || !resumed.IsDone(4) || resumed.IsDone(2) || resumed.Len() != 2 {
+		t.Errorf("resumed checkpoint done lines = %v", resumed.DoneLines)
+	}
+	if !reflect.DeepEqual(resumed.DoneLines, []int{1, 4}) {
+		t.Errorf("DoneLines = %v, want sorted [1 4]", resumed.DoneLines)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary checkpoint file left behind: %v", err)
+	}
+
+	if err := resumed.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := resumed.Remove(); err != nil {
+		t.Fatalf("Remove of a missing checkpoint: %v", err)
+	}
+	fresh, err := LoadCheckpoint(path, "users.csv", digest)
+	if err != nil || fresh.Len() != 0 {
+		t.Fatalf("LoadCheckpoint after Remove = %d lines, %v", fresh.Len(), err)
+	}
+}
+
+func TestCheckpointRejectsOtherFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc.json")
+	cp, err := LoadCheckpoint(path, "users.csv", Digest([]byte("old")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.MarkDone(1); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadCheckpoint(path, "users.csv", Digest([]byte("new")))
+	if err == nil || !strings.Contains(err.Error(), "different CSV file") {
+		t.Fatalf("LoadCheckpoint error = %v, want a digest mismatch", err)
+	}
+}
+
+func TestCheckpointRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCheckpoint(path, "users.csv", Digest(nil)); err == nil {
+		t.Fatal("LoadCheckpoint accepted a corrupt checkpoint")
+	}
+}
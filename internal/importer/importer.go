@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	awsvc "aws-groups-manager/internal/aws"
+)
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+var ErrThrottled = errors.New("throttled by AWS")
+
+type Row struct {
+	Line          int
+	Group         string
+	User          string
+	Account       string
+	PermissionSet string
+}
+
+func (r Row) IsAssignment() bool {
+	return r.Account != "" || r.PermissionSet != ""
+}
+
+type Op struct {
+	Row        Row
+	Group      awsvc.Group
+	User       awsvc.User
+	Assignment awsvc.Assignment
+	Exists     bool
+	Err        error
+}
+
+func (o Op) Action() string {
+	if o.Row.IsAssignment() {
+		return "CreateAssignment"
+	}
+	return "AddUserToGroup"
+}
+
+func (o Op) Label() string {
+	if o.Row.IsAssignment() {
+		account := cmp.Or(o.Assignment.AccountName, o.Assignment.AccountID, o.Row.Account)
+		ps := cmp.Or(o.Assignment.PermissionSetName, o.Row.PermissionSet)
+		return fmt.Sprintf("%s on %s -> %s", ps, account, cmp.Or(o.Group.DisplayName, o.Row.Group))
+	}
+	user := cmp.Or(o.User.UserName, o.User.DisplayName, o.Row.User)
+	return fmt.Sprintf("%s -> %s", user, cmp.Or(o.Group.DisplayName, o.Row.Group))
+}
+
+type Plan struct {
+	Ops []Op
+}
+
+func (p Plan) Invalid() []Op {
+	ops := make([]Op, 0)
+	for _, op := range p.Ops {
+		if op.Err != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (p Plan) Pending() []Op {
+	ops := make([]Op, 0, len(p.Ops))
+	for _, op := range p.Ops {
+		if op.Err == nil && !op.Exists {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func Parse(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	rows := make([]Row, 0, 64)
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		if isBlank(record) {
+			continue
+		}
+		if first {
+			first = false
+			if strings.EqualFold(record[0], "group") {
+				continue
+			}
+		}
+
+		switch len(record) {
+		case 2:
+			rows = append(rows, Row{Line: line, Group: record[0], User: record[1]})
+		case 3:
+			rows = append(rows, Row{Line: line, Group: record[0], Account: record[1], PermissionSet: record[2]})
+		default:
+			return nil, fmt.Errorf("line %d: expected group,user or group,account,permission_set, got %d columns", line, len(record))
+		}
+	}
+
+	return rows, nil
+}
+
+func Validate(ctx context.Context, svc *awsvc.Service, rows []Row) (Plan, error) {
+	groups, err := svc.ListGroups(ctx)
+	if err != nil {
+		return Plan{}, fmt.Errorf("list groups: %w", err)
+	}
+	groupsByKey := make(map[string]awsvc.Group, len(groups)*2)
+	for _, g := range groups {
+		groupsByKey[g.ID] = g
+		groupsByKey[strings.ToLower(g.DisplayName)] = g
+	}
+
+	var permissionSets []awsvc.PermissionSet
+	var accounts []awsvc.Account
+	accountsKnown := false
+	if hasAssignments(rows) {
+		permissionSets, err = svc.ListPermissionSets(ctx)
+		if err != nil {
+			return Plan{}, fmt.Errorf("list permission sets: %w", err)
+		}
+		accounts, err = svc.ListAccounts(ctx)
+		switch {
+		case err == nil:
+			accountsKnown = true
+		case errors.Is(err, awsvc.ErrOrganizationsAccessDenied):
+		default:
+			return Plan{}, fmt.Errorf("list accounts: %w", err)
+		}
+	}
+	permissionSetsByKey := make(map[string]awsvc.PermissionSet, len(permissionSets)*2)
+	for _, ps := range permissionSets {
+		permissionSetsByKey[ps.ARN] = ps
+		permissionSetsByKey[strings.ToLower(ps.Name)] = ps
+	}
+	accountsByKey := make(map[string]awsvc.Account, len(accounts)*2)
+	for _, a := range accounts {
+		accountsByKey[a.ID] = a
+		accountsByKey[strings.ToLower(a.Name)] = a
+	}
+
+	users := make(map[string]userLookup)
+	members := make(map[string]map[string]bool)
+	assignments := make(map[string]map[string]bool)
+
+	plan := Plan{Ops: make([]Op, 0, len(rows))}
+	for _, row := range rows {
+		op := Op{Row: row}
+
+		group, ok := groupsByKey[row.Group]
+		if !ok {
+			group, ok = groupsByKey[strings.ToLower(row.Group)]
+		}
+		if !ok {
+			op.Err = fmt.Errorf("group %q not found", row.Group)
+			plan.Ops = append(plan.Ops, op)
+			continue
+		}
+		op.Group = group
+
+		if !row.IsAssignment() {
+			lookup, ok := users[strings.ToLower(row.User)]
+			if !ok {
+				lookup.user, lookup.err = svc.FindUser(ctx, row.User)
+				users[strings.ToLower(row.User)] = lookup
+			}
+			if lookup.err != nil {
+				op.Err = fmt.Errorf("user %q: %w", row.User, lookup.err)
+				plan.Ops = append(plan.Ops, op)
+				continue
+			}
+			op.User = lookup.user
+
+			if _, ok := members[group.ID]; !ok {
+				groupUsers, err := svc.ListGroupUsers(ctx, group.ID)
+				if err != nil {
+					return Plan{}, fmt.Errorf("list members of %s: %w", group.DisplayName, err)
+				}
+				members[group.ID] = make(map[string]bool, len(groupUsers))
+				for _, u := range groupUsers {
+					members[group.ID][u.UserID] = true
+				}
+			}
+			op.Exists = members[group.ID][op.User.ID]
+			members[group.ID][op.User.ID] = true
+			plan.Ops = append(plan.Ops, op)
+			continue
+		}
+
+		ps, ok := permissionSetsByKey[row.PermissionSet]
+		if !ok {
+			ps, ok = permissionSetsByKey[strings.ToLower(row.PermissionSet)]
+		}
+		if !ok {
+			op.Err = fmt.Errorf("permission set %q not found", row.PermissionSet)
+			plan.Ops = append(plan.Ops, op)
+			continue
+		}
+
+		account, ok := accountsByKey[row.Account]
+		if !ok {
+			account, ok = accountsByKey[strings.ToLower(row.Account)]
+		}
+		switch {
+		case ok:
+		case accountsKnown:
+			op.Err = fmt.Errorf("account %q not found in the organization", row.Account)
+		case accountIDPattern.MatchString(row.Account):
+			account = awsvc.Account{ID: row.Account}
+		default:
+			op.Err = fmt.Errorf("account %q is not a 12-digit account ID", row.Account)
+		}
+		if op.Err != nil {
+			plan.Ops = append(plan.Ops, op)
+			continue
+		}
+
+		op.Assignment = awsvc.Assignment{
+			AccountID:         account.ID,
+			AccountName:       account.Name,
+			PermissionSetARN:  ps.ARN,
+			PermissionSetName: ps.Name,
+		}
+
+		if _, ok := assignments[group.ID]; !ok {
+			existing, err := svc.ListGroupAssignments(ctx, group.ID)
+			if err != nil {
+				return Plan{}, fmt.Errorf("list assignments of %s: %w", group.DisplayName, err)
+			}
+			assignments[group.ID] = make(map[string]bool, len(existing))
+			for _, a := range existing {
+				assignments[group.ID][a.AccountID+"|"+a.PermissionSetARN] = true
+			}
+		}
+		key := account.ID + "|" + ps.ARN
+		op.Exists = assignments[group.ID][key]
+		assignments[group.ID][key] = true
+		plan.Ops = append(plan.Ops, op)
+	}
+
+	return plan, nil
+}
+
+func Apply(ctx context.Context, svc *awsvc.Service, plan Plan, checkpoint *Checkpoint) (awsvc.ChangeReport, error) {
+	report := awsvc.ChangeReport{}
+
+	for _, op := range plan.Pending() {
+		if checkpoint.IsDone(op.Row.Line) {
+			continue
+		}
+
+		var err error
+		if op.Row.IsAssignment() {
+			err = svc.CreateAssignment(ctx, op.Group.ID, op.Assignment.AccountID, op.Assignment.PermissionSetARN)
+		} else {
+			_, err = svc.AddUserToGroup(ctx, op.Group.ID, op.User.ID)
+		}
+		report.Results = append(report.Results, awsvc.ChangeResult{
+			Action: op.Action(),
+			Target: fmt.Sprintf("line %d: %s", op.Row.Line, op.Label()),
+			Err:    err,
+		})
+
+		if err != nil {
+			if awsvc.IsThrottling(err) {
+				return report, fmt.Errorf("line %d: %w: %v", op.Row.Line, ErrThrottled, err)
+			}
+			continue
+		}
+
+		if err := checkpoint.MarkDone(op.Row.Line); err != nil {
+			return report, fmt.Errorf("save checkpoint: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+type userLookup struct {
+	user awsvc.User
+	err  error
+}
+
+func hasAssignments(rows []Row) bool {
+	for _, row := range rows {
+		if row.IsAssignment() {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if field != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	awsvc "aws-groups-manager/internal/aws"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Row
+	}{
+		{
+			name:  "header and both row kinds",
+			input: "group,user_or_account,permission_set\nAdmins, alice\nAdmins,123456789012, AdministratorAccess\n",
+			want: []Row{
+				{Line: 2, Group: "Admins", User: "alice"},
+				{Line: 3, Group: "Admins", Account: "123456789012", PermissionSet: "AdministratorAccess"},
+			},
+		},
+		{
+			name:  "comments and blank lines keep file line numbers",
+			input: "# members\n\nDevelopers,bob\n , \nDevelopers,carol\n",
+			want: []Row{
+				{Line: 3, Group: "Developers", User: "bob"},
+				{Line: 5, Group: "Developers", User: "carol"},
+			},
+		},
+		{
+			name:  "quoted fields",
+			input: "\"Ops, EU\",\"dave\"\n",
+			want:  []Row{{Line: 1, Group: "Ops, EU", User: "dave"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsBadColumns(t *testing.T) {
+	_, err := Parse(strings.NewReader("Admins,alice\nAdmins\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Parse error = %v, want a line 2 column error", err)
+	}
+}
+
+func TestPlanFilters(t *testing.T) {
+	plan := Plan{Ops: []Op{
+		{Row: Row{Line: 1, Group: "Admins", User: "alice"}},
+		{Row: Row{Line: 2, Group: "Admins", User: "bob"}, Exists: true},
+		{Row: Row{Line: 3, Group: "Missing", User: "carol"}, Err: errors.New("group not found")},
+	}}
+
+	if pending := plan.Pending(); len(pending) != 1 || pending[0].Row.Line != 1 {
+		t.Errorf("Pending = %+v, want only line 1", pending)
+	}
+	if invalid := plan.Invalid(); len(invalid) != 1 || invalid[0].Row.Line != 3 {
+		t.Errorf("Invalid = %+v, want only line 3", invalid)
+	}
+}
+
+func TestOpLabel(t *testing.T) {
+	tests := []struct {
+		op         Op
+		wantAction string
+		wantLabel  string
+	}{
+		{
+			op:         Op{Row: Row{Group: "admins", User: "alice"}, Group: awsvc.Group{DisplayName: "Admins"}, User: awsvc.User{UserName: "alice@example.com"}},
+			wantAction: "AddUserToGroup",
+			wantLabel:  "alice@example.com -> Admins",
+		},
+		{
+			op:         Op{Row: Row{Group: "Admins", Account: "123456789012", PermissionSet: "ReadOnly"}},
+			wantAction: "CreateAssignment",
+			wantLabel:  "ReadOnly on 123456789012 -> Admins",
+		},
+		{
+			op: Op{
+				Row:        Row{Group: "Admins", Account: "prod", PermissionSet: "readonly"},
+				Assignment: awsvc.Assignment{AccountID: "123456789012", AccountName: "Production", PermissionSetName: "ReadOnly"},
+			},
+			wantAction: "CreateAssignment",
+			wantLabel:  "ReadOnly on Production -> Admins",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.op.Action(); got != tt.wantAction {
+			t.Errorf("Action = %q, want %q", got, tt.wantAction)
+		}
+		if got := tt.op.Label(); got != tt.wantLabel {
+			t.Errorf("Label = %q, want %q", got, tt.wantLabel)
+		}
+	}
+}
+
+func TestApplySkipsCheckpointedLines(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "import.json"), "users.csv", Digest([]byte("csv")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []int{2, 3} {
+		if err := cp.MarkDone(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan := Plan{Ops: []Op{
+		{Row: Row{Line: 2, Group: "Admins", User: "alice"}},
+		{Row: Row{Line: 3, Group: "Admins", Account: "123456789012", PermissionSet: "ReadOnly"}},
+	}}
+
+	report, err := Apply(context.Background(), nil, plan, cp)
+	if err != nil || len(report.Results) != 0 {
+		t.Fatalf("Apply = %+v, %v; want every line skipped", report.Results, err)
+	}
+}
@@ -0,0 +1,153 @@
+package offboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aws-groups-manager/internal/audit"
+	awsvc "aws-groups-manager/internal/aws"
+	"aws-groups-manager/internal/signing"
+)
+
+type ReportUser struct {
+	ID          string `json:"id"`
+	UserName    string `json:"user_name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+type ReportEntry struct {
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Operator    string        `json:"operator"`
+	Profile     string        `json:"profile"`
+	Region      string        `json:"region"`
+	InstanceARN string        `json:"instance_arn"`
+	User        ReportUser    `json:"user"`
+	Removed     []ReportEntry `json:"removed"`
+	Failed      []ReportEntry `json:"failed"`
+	PublicKey   string        `json:"public_key,omitempty"`
+	Signature   string        `json:"signature,omitempty"`
+}
+
+func NewReport(svc *awsvc.Service, plan awsvc.OffboardPlan, changes awsvc.ChangeReport) Report {
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Operator:    audit.OSUser(),
+		Profile:     svc.Profile(),
+		Region:      svc.Region(),
+		InstanceARN: svc.InstanceARN(),
+		User: ReportUser{
+			ID:          plan.User.ID,
+			UserName:    plan.User.UserName,
+			DisplayName: plan.User.DisplayName,
+			Email:       plan.User.Email,
+		},
+		Removed: []ReportEntry{},
+		Failed:  []ReportEntry{},
+	}
+
+	for _, res := range changes.Results {
+		entry := ReportEntry{Action: res.Action, Target: res.Target}
+		if res.Err != nil {
+			entry.Error = res.Err.Error()
+			report.Failed = append(report.Failed, entry)
+			continue
+		}
+		report.Removed = append(report.Removed, entry)
+	}
+
+	return report
+}
+
+func (r *Report) Sign(keyPath string) error {
+	key, err := signing.LoadOrCreateKey(keyPath)
+	if err != nil {
+		return fmt.Errorf("load signing key: %w", err)
+	}
+
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	r.PublicKey, r.Signature = signing.Sign(key, payload)
+	return nil
+}
+
+func (r Report) Verify(trustedKey string) error {
+	if r.Signature == "" || r.PublicKey == "" {
+		return fmt.Errorf("report is not signed")
+	}
+	if r.PublicKey != trustedKey {
+		return signing.ErrUntrustedKey
+	}
+
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	return signing.Verify(trustedKey, r.Signature, payload)
+}
+
+func (r Report) payload() ([]byte, error) {
+	r.PublicKey = ""
+	r.Signature = ""
+	return json.Marshal(r)
+}
+
+func (r Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func ReadReport(path string) (Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+func DefaultReportPath(user awsvc.User, at time.Time) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "reports", fileName(user, at)), nil
+}
+
+func fileName(user awsvc.User, at time.Time) string {
+	name := user.UserName
+	if name == "" {
+		name = user.ID
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf("offboard-%s-%s.json", name, at.UTC().Format("20060102T150405Z"))
+}
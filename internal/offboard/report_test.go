@@ -0,0 +1,72 @@
+package offboard
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aws-groups-manager/internal/signing"
+)
+
+func signedReport(t *testing.T, keyPath string) Report {
+	t.Helper()
+	report := Report{
+		Operator: "alice",
+		User:     ReportUser{ID: "user-1", UserName: "bob"},
+		Removed:  []ReportEntry{{Action: "RemoveUserFromGroup", Target: "admins (g-1)"}},
+		Failed:   []ReportEntry{},
+	}
+	if err := report.Sign(keyPath); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return report
+}
+
+func TestReportVerify(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "signing.key")
+	otherPath := filepath.Join(dir, "other.key")
+
+	report := signedReport(t, keyPath)
+	trusted, err := signing.LoadPublicKey(keyPath)
+	if err != nil {
+		t.Fatalf("LoadPublicKey: %v", err)
+	}
+	if err := report.Verify(trusted); err != nil {
+		t.Fatalf("Verify untouched report: %v", err)
+	}
+
+	tampered := report
+	tampered.Removed = append([]ReportEntry{}, report.Removed...)
+	tampered.Removed[0].Target = "auditors (g-2)"
+	if err := tampered.Verify(trusted); !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Fatalf("Verify tampered report: got %v, want ErrInvalidSignature", err)
+	}
+
+	if err := tampered.Sign(otherPath); err != nil {
+		t.Fatalf("re-sign: %v", err)
+	}
+	if err := tampered.Verify(trusted); !errors.Is(err, signing.ErrUntrustedKey) {
+		t.Fatalf("Verify re-signed report: got %v, want ErrUntrustedKey", err)
+	}
+
+	unsigned := report
+	unsigned.Signature = ""
+	if err := unsigned.Verify(trusted); err == nil {
+		t.Fatal("Verify unsigned report: want error")
+	}
+}
+
+func TestFingerprintIsStable(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "signing.key")
+	report := signedReport(t, keyPath)
+
+	a, err := signing.Fingerprint(report.PublicKey)
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	b, _ := signing.Fingerprint(signedReport(t, keyPath).PublicKey)
+	if a != b {
+		t.Fatalf("fingerprints differ for the same key: %s vs %s", a, b)
+	}
+}
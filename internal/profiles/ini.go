@@ -0,0 +1,96 @@
+package profiles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type section struct {
+	name       string
+	keys       map[string]string
+	subsection map[string]map[string]string
+}
+
+func parseINI(r io.Reader) ([]section, error) {
+	var (
+		sections []section
+		current  *section
+		lastKey  string
+		nested   map[string]string
+	)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		indented := raw[0] == ' ' || raw[0] == '\t'
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNo)
+			}
+			name := strings.Join(strings.Fields(line[1:end]), " ")
+			sections = append(sections, section{name: name, keys: make(map[string]string), subsection: make(map[string]map[string]string)})
+			current = &sections[len(sections)-1]
+			lastKey, nested = "", nil
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: property outside of a section", lineNo)
+		}
+
+		if indented && lastKey != "" {
+			key, value, ok := strings.Cut(line, "=")
+			switch {
+			case nested != nil && ok:
+				nested[normalizeKey(key)] = stripComment(value)
+			case nested == nil:
+				current.keys[lastKey] += "\n" + stripComment(line)
+			default:
+				return nil, fmt.Errorf("line %d: expected key = value in %s", lineNo, lastKey)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		lastKey = normalizeKey(key)
+		value = stripComment(value)
+		if value == "" {
+			nested = make(map[string]string)
+			current.subsection[lastKey] = nested
+			current.keys[lastKey] = ""
+			continue
+		}
+		nested = nil
+		current.keys[lastKey] = value
+	}
+
+	return sections, scanner.Err()
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func stripComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if (value[i] == '#' || value[i] == ';') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(value)
+}
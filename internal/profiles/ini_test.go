@@ -0,0 +1,91 @@
+package profiles
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []section
+	}{
+		{
+			name:  "sections and keys",
+			input: "[default]\nregion = eu-west-1\n\n[profile dev]\nRegion=us-east-1\n",
+			want: []section{
+				{name: "default", keys: map[string]string{"region": "eu-west-1"}, subsection: map[string]map[string]string{}},
+				{name: "profile dev", keys: map[string]string{"region": "us-east-1"}, subsection: map[string]map[string]string{}},
+			},
+		},
+		{
+			name:  "comments",
+			input: "# leading\n; also leading\n[profile  dev ]\nregion = eu-west-1 # inline\nsso_start_url = https://example.awsapps.com/start#/ ; trailing\nrole_arn = arn:aws:iam::123456789012:role/a#b\n",
+			want: []section{
+				{name: "profile dev", keys: map[string]string{
+					"region":        "eu-west-1",
+					"sso_start_url": "https://example.awsapps.com/start#/",
+					"role_arn":      "arn:aws:iam::123456789012:role/a#b",
+				}, subsection: map[string]map[string]string{}},
+			},
+		},
+		{
+			name:  "nested s3 block",
+			input: "[profile dev]\ns3 =\n  max_concurrent_requests = 20\n  addressing_style = path\nregion = eu-west-1\n",
+			want: []section{
+				{name: "profile dev", keys: map[string]string{"s3": "", "region": "eu-west-1"}, subsection: map[string]map[string]string{
+					"s3": {"max_concurrent_requests": "20", "addressing_style": "path"},
+				}},
+			},
+		},
+		{
+			name:  "continuation line",
+			input: "[profile dev]\ncredential_process = /usr/bin/creds\n  --profile dev\n",
+			want: []section{
+				{name: "profile dev", keys: map[string]string{"credential_process": "/usr/bin/creds\n--profile dev"}, subsection: map[string]map[string]string{}},
+			},
+		},
+		{
+			name:  "sso-session",
+			input: "[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = eu-central-1\nsso_registration_scopes = sso:account:access\n",
+			want: []section{
+				{name: "sso-session corp", keys: map[string]string{
+					"sso_start_url":           "https://corp.awsapps.com/start",
+					"sso_region":              "eu-central-1",
+					"sso_registration_scopes": "sso:account:access",
+				}, subsection: map[string]map[string]string{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseINI(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("parseINI: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseINI =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseINIErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated header": "[profile dev\nregion = eu-west-1\n",
+		"key outside section": "region = eu-west-1\n",
+		"missing equals":      "[default]\nregion\n",
+		"bad nested line":     "[default]\ns3 =\n  max_concurrent_requests\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseINI(strings.NewReader(input)); err == nil {
+				t.Fatal("parseINI accepted invalid input")
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package profiles
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const NoProfile = "none"
+
+type Profile struct {
+	Name              string
+	Region            string
+	SSOSession        string
+	SSOStartURL       string
+	SSORegion         string
+	SSOAccountID      string
+	SSORoleName       string
+	SourceProfile     string
+	RoleARN           string
+	CredentialProcess string
+	StaticKeys        bool
+}
+
+type SSOSession struct {
+	Name     string
+	StartURL string
+	Region   string
+}
+
+type Config struct {
+	Profiles    map[string]*Profile
+	SSOSessions map[string]SSOSession
+}
+
+func (p Profile) IsSSO() bool {
+	return p.SSOStartURL != "" || p.SSOSession != ""
+}
+
+func (p Profile) IdentityCenterRegion() string {
+	return cmp.Or(p.SSORegion, p.Region)
+}
+
+func (p Profile) Description() string {
+	parts := make([]string, 0, 4)
+	switch {
+	case p.IsSSO():
+		if p.SSOAccountID != "" || p.SSORoleName != "" {
+			parts = append(parts, fmt.Sprintf("SSO %s / %s", cmp.Or(p.SSOAccountID, "-"), cmp.Or(p.SSORoleName, "-")))
+		} else {
+			parts = append(parts, "SSO")
+		}
+		if p.SSOStartURL != "" {
+			parts = append(parts, p.SSOStartURL)
+		}
+	case p.RoleARN != "":
+		parts = append(parts, "role "+p.RoleARN)
+	case p.CredentialProcess != "":
+		parts = append(parts, "credential_process")
+	case p.StaticKeys:
+		parts = append(parts, "access keys")
+	}
+	if p.SourceProfile != "" {
+		parts = append(parts, "source "+p.SourceProfile)
+	}
+	if p.Region != "" {
+		parts = append(parts, "region "+p.Region)
+	}
+	if len(parts) == 0 {
+		return "AWS profile"
+	}
+	return strings.Join(parts, " | ")
+}
+
+func ConfigPath() (string, error) {
+	return envPath("AWS_CONFIG_FILE", "config")
+}
+
+func CredentialsPath() (string, error) {
+	return envPath("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+}
+
+func envPath(env, name string) (string, error) {
+	if path := os.Getenv(env); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aws", name), nil
+}
+
+func Load() (Config, error) {
+	cfg := Config{Profiles: make(map[string]*Profile), SSOSessions: make(map[string]SSOSession)}
+
+	configPath, err := ConfigPath()
+	if err != nil {
+		return Config{}, err
+	}
+	credentialsPath, err := CredentialsPath()
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.readFile(configPath, false); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.readFile(credentialsPath, true); err != nil {
+		return Config{}, err
+	}
+
+	for _, p := range cfg.Profiles {
+		session, ok := cfg.SSOSessions[p.SSOSession]
+		if !ok {
+			continue
+		}
+		p.SSOStartURL = cmp.Or(p.SSOStartURL, session.StartURL)
+		p.SSORegion = cmp.Or(p.SSORegion, session.Region)
+	}
+
+	return cfg, nil
+}
+
+func (c Config) SSOProfile(name string) (Profile, bool) {
+	for range len(c.Profiles) {
+		p, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, false
+		}
+		if p.IsSSO() {
+			return *p, true
+		}
+		if p.SourceProfile == "" || p.SourceProfile == name {
+			return Profile{}, false
+		}
+		name = p.SourceProfile
+	}
+	return Profile{}, false
+}
+
+func Region(name string) string {
+	if name == NoProfile {
+		return EnvRegion()
+	}
+	cfg, err := Load()
+	if err != nil {
+		return ""
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return ""
+	}
+	return p.IdentityCenterRegion()
+}
+
+func EnvRegion() string {
+	return cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+}
+
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c Config) Sorted() []Profile {
+	list := make([]Profile, 0, len(c.Profiles))
+	for _, name := range c.Names() {
+		list = append(list, *c.Profiles[name])
+	}
+	return list
+}
+
+func (c Config) readFile(path string, credentials bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sections, err := parseINI(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, s := range sections {
+		kind, name, _ := strings.Cut(s.name, " ")
+		switch {
+		case credentials:
+			p := c.profile(s.name)
+			p.StaticKeys = p.StaticKeys || s.keys["aws_access_key_id"] != ""
+		case kind == "sso-session" && name != "":
+			session := c.SSOSessions[name]
+			session.Name = name
+			session.StartURL = cmp.Or(s.keys["sso_start_url"], session.StartURL)
+			session.Region = cmp.Or(s.keys["sso_region"], session.Region)
+			c.SSOSessions[name] = session
+		case kind == "profile" && name != "":
+			c.profile(name).apply(s.keys)
+		case s.name == "default":
+			c.profile(s.name).apply(s.keys)
+		}
+	}
+
+	return nil
+}
+
+func (c Config) profile(name string) *Profile {
+	p, ok := c.Profiles[name]
+	if !ok {
+		p = &Profile{Name: name}
+		c.Profiles[name] = p
+	}
+	return p
+}
+
+func (p *Profile) apply(keys map[string]string) {
+	fields := map[string]*string{
+		"region":             &p.Region,
+		"sso_session":        &p.SSOSession,
+		"sso_start_url":      &p.SSOStartURL,
+		"sso_region":         &p.SSORegion,
+		"sso_account_id":     &p.SSOAccountID,
+		"sso_role_name":      &p.SSORoleName,
+		"source_profile":     &p.SourceProfile,
+		"role_arn":           &p.RoleARN,
+		"credential_process": &p.CredentialProcess,
+	}
+	for key, dst := range fields {
+		*dst = cmp.Or(keys[key], *dst)
+	}
+}
@@ -0,0 +1,169 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `[default]
+region = us-east-1
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-central-1
+
+[profile admin]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+region = eu-west-1 # working region
+
+[profile legacy-sso]
+sso_start_url = https://legacy.awsapps.com/start
+sso_region = us-west-2
+sso_account_id = 222222222222
+sso_role_name = ReadOnly
+
+[profile admin]
+s3 =
+  addressing_style = path
+
+[profile audit]
+role_arn = arn:aws:iam::333333333333:role/audit
+source_profile = admin
+
+[profile chained]
+role_arn = arn:aws:iam::444444444444:role/chained
+source_profile = audit
+
+[profile keys]
+role_arn = arn:aws:iam::555555555555:role/keys
+source_profile = static
+
+[profile loop]
+role_arn = arn:aws:iam::666666666666:role/loop
+source_profile = loop
+`
+
+const testCredentials = `[static]
+aws_access_key_id = AKIAEXAMPLE
+aws_secret_access_key = secret
+
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = secret
+
+[static]
+aws_session_token = token
+`
+
+func loadTestConfig(t *testing.T) Config {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configPath, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsPath, []byte(testCredentials), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestLoad(t *testing.T) {
+	cfg := loadTestConfig(t)
+
+	admin := cfg.Profiles["admin"]
+	if admin == nil {
+		t.Fatal("profile admin missing")
+	}
+	if admin.Region != "eu-west-1" || admin.SSOAccountID != "111111111111" || admin.SSORoleName != "Admin" {
+		t.Errorf("repeated [profile admin] lost earlier fields: %+v", *admin)
+	}
+	if admin.SSOStartURL != "https://corp.awsapps.com/start" || admin.SSORegion != "eu-central-1" {
+		t.Errorf("admin did not inherit the sso-session: %+v", *admin)
+	}
+	if got := admin.IdentityCenterRegion(); got != "eu-central-1" {
+		t.Errorf("admin IdentityCenterRegion = %q", got)
+	}
+
+	static := cfg.Profiles["static"]
+	if static == nil || !static.StaticKeys {
+		t.Fatalf("credentials-only profile static not loaded with keys: %+v", static)
+	}
+	if static.IsSSO() || static.Description() != "access keys" {
+		t.Errorf("static described as %q", static.Description())
+	}
+
+	def := cfg.Profiles["default"]
+	if def == nil || def.Region != "us-east-1" || !def.StaticKeys {
+		t.Errorf("default should merge config and credentials: %+v", def)
+	}
+
+	want := []string{"admin", "audit", "chained", "default", "keys", "legacy-sso", "loop", "static"}
+	if got := cfg.Names(); len(got) != len(want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+}
+
+func TestSSOProfile(t *testing.T) {
+	cfg := loadTestConfig(t)
+
+	tests := []struct {
+		name     string
+		want     string
+		wantSSO  bool
+		startURL string
+	}{
+		{name: "admin", want: "admin", wantSSO: true, startURL: "https://corp.awsapps.com/start"},
+		{name: "legacy-sso", want: "legacy-sso", wantSSO: true, startURL: "https://legacy.awsapps.com/start"},
+		{name: "audit", want: "admin", wantSSO: true, startURL: "https://corp.awsapps.com/start"},
+		{name: "chained", want: "admin", wantSSO: true, startURL: "https://corp.awsapps.com/start"},
+		{name: "keys"},
+		{name: "loop"},
+		{name: "static"},
+		{name: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := cfg.SSOProfile(tt.name)
+			if ok != tt.wantSSO {
+				t.Fatalf("SSOProfile(%q) ok = %v, want %v", tt.name, ok, tt.wantSSO)
+			}
+			if !ok {
+				return
+			}
+			if p.Name != tt.want || p.SSOStartURL != tt.startURL {
+				t.Errorf("SSOProfile(%q) = %s (%s), want %s (%s)", tt.name, p.Name, p.SSOStartURL, tt.want, tt.startURL)
+			}
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	loadTestConfig(t)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+
+	tests := map[string]string{
+		"admin":      "eu-central-1",
+		"legacy-sso": "us-west-2",
+		"missing":    "",
+		NoProfile:    "ap-south-1",
+	}
+	for name, want := range tests {
+		if got := Region(name); got != want {
+			t.Errorf("Region(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
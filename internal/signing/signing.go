@@ -0,0 +1,124 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidSignature = errors.New("signature does not match")
+
+var ErrUntrustedKey = errors.New("signed with an untrusted key")
+
+func DefaultKeyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "aws-groups-manager", "signing.key"), nil
+}
+
+func LoadOrCreateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return parseKey(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func LoadPublicKey(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	key, err := parseKey(data)
+	if err != nil {
+		return "", err
+	}
+	return PublicKey(key), nil
+}
+
+func PublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+func Fingerprint(publicKey string) (string, error) {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("decode public key: %w", err)
+	}
+	sum := sha256.Sum256(pub)
+	return "SHA256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func Sign(key ed25519.PrivateKey, payload []byte) (publicKey, signature string) {
+	sig := ed25519.Sign(key, payload)
+	return PublicKey(key), base64.StdEncoding.EncodeToString(sig)
+}
+
+func Verify(publicKey, signature string, payload []byte) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("decode public key: %w", err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key length %d", len(pub))
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pub), payload, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func parseKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is not an ed25519 key")
+	}
+	return key, nil
+}
@@ -1,6 +1,10 @@
 package theme
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 type Styles struct {
 	App             lipgloss.Style
@@ -22,6 +26,17 @@ type Styles struct {
 	InlineHighlight lipgloss.Style
 }
 
+func ByName(name string) (Styles, error) {
+	switch name {
+	case "", "dark":
+		return Dark(), nil
+	case "high-contrast":
+		return HighContrast(), nil
+	default:
+		return Styles{}, fmt.Errorf("unknown theme %q (available: dark, high-contrast)", name)
+	}
+}
+
 func Dark() Styles {
 	base := lipgloss.NewStyle().Background(lipgloss.Color("#0B0F10")).Foreground(lipgloss.Color("#E8EEF0"))
 
@@ -46,3 +61,27 @@ func Dark() Styles {
 		InlineHighlight: base.Foreground(lipgloss.Color("#9FD2FF")).Bold(true),
 	}
 }
+
+func HighContrast() Styles {
+	base := lipgloss.NewStyle().Background(lipgloss.Color("#000000")).Foreground(lipgloss.Color("#FFFFFF"))
+
+	return Styles{
+		App:             base.Padding(0, 1),
+		Header:          base.Background(lipgloss.Color("#002B4D")).Foreground(lipgloss.Color("#FFFFFF")).Bold(true).Padding(0, 1),
+		Body:            base,
+		StatusInfo:      base.Background(lipgloss.Color("#003A66")).Foreground(lipgloss.Color("#FFFFFF")).Padding(0, 1),
+		StatusWarn:      base.Background(lipgloss.Color("#5C4200")).Foreground(lipgloss.Color("#FFE066")).Bold(true).Padding(0, 1),
+		StatusError:     base.Background(lipgloss.Color("#660000")).Foreground(lipgloss.Color("#FFFFFF")).Bold(true).Padding(0, 1),
+		Footer:          base.Background(lipgloss.Color("#1A1A1A")).Foreground(lipgloss.Color("#FFFFFF")).Padding(0, 1),
+		SelectedTitle:   base.Background(lipgloss.Color("#FFE066")).Foreground(lipgloss.Color("#000000")).Bold(true),
+		SelectedSub:     base.Background(lipgloss.Color("#FFE066")).Foreground(lipgloss.Color("#1A1A1A")),
+		NormalTitle:     base.Foreground(lipgloss.Color("#FFFFFF")),
+		NormalSub:       base.Foreground(lipgloss.Color("#C8C8C8")),
+		Modal:           base.Border(lipgloss.ThickBorder()).BorderForeground(lipgloss.Color("#FFE066")).Padding(1, 2),
+		ModalTitle:      base.Foreground(lipgloss.Color("#FFE066")).Bold(true),
+		ModalHint:       base.Foreground(lipgloss.Color("#E0E0E0")),
+		TabActive:       base.Background(lipgloss.Color("#FFE066")).Foreground(lipgloss.Color("#000000")).Bold(true).Padding(0, 1),
+		TabInactive:     base.Background(lipgloss.Color("#262626")).Foreground(lipgloss.Color("#FFFFFF")).Padding(0, 1),
+		InlineHighlight: base.Foreground(lipgloss.Color("#66CCFF")).Bold(true),
+	}
+}